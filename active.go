@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// Active describes the active tab of the front window.
+type Active struct {
+	WindowID int    `json:"windowId" yaml:"windowId"`
+	TabIndex int    `json:"tabIndex" yaml:"tabIndex"`
+	TabID    string `json:"tabId" yaml:"tabId"`
+	Title    string `json:"title" yaml:"title"`
+	URL      string `json:"url" yaml:"url"`
+	Space    string `json:"space" yaml:"space"`
+}
+
+func (a Active) porcelainRow() []string {
+	return []string{strconv.Itoa(a.WindowID), strconv.Itoa(a.TabIndex), a.TabID, a.Space, a.Title, a.URL}
+}
+
+// defaultActiveFormat is the template used to print the active tab when no output flag is set.
+const defaultActiveFormat = `{{.WindowID}}:{{.TabIndex}} [{{.Space}}] {{.Title}} {{.URL}}`
+
+func NewCmdActive() *cobra.Command {
+	var flags struct {
+		outputFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:   "active",
+		Short: "Print the active tab of the front window",
+		Long: `Print the active tab of the front window, along with its window and space, in a single call.
+
+The default output is a single line, formatted as ` + "`" + defaultActiveFormat + "`" + `.
+The space is empty when Arc does not report it, e.g. for little arc windows.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tab, space, err := getActiveTabAndSpace(0)
+			if err != nil {
+				return err
+			}
+
+			active := Active{
+				WindowID: tab.WindowID,
+				TabIndex: tab.Index,
+				TabID:    tab.ID,
+				URL:      tab.URL,
+				Space:    space,
+				Title:    tab.Title,
+			}
+
+			if ok, err := printItem(cmd.OutOrStdout(), flags.outputFlags, active); ok || err != nil {
+				return err
+			}
+
+			return printTemplate(cmd.OutOrStdout(), defaultActiveFormat, []Active{active})
+		},
+	}
+
+	addOutputFlags(cmd, &flags.outputFlags)
+	return cmd
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCloseAll() *cobra.Command {
+	var flags struct {
+		Incognito bool
+		Normal    bool
+		KeepFront bool
+		Yes       bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "close-all",
+		Short: "Close every window",
+		Long: `Close every window, or only the incognito or normal ones.
+
+Closing windows cannot be undone, so the command asks for confirmation, unless --yes is set; when not
+running in a terminal, --yes is required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windows, err := listWindows()
+			if err != nil {
+				return err
+			}
+
+			var windowIDs []int
+			for _, window := range windows {
+				if (flags.Incognito && !window.Incognito) || (flags.Normal && window.Incognito) {
+					continue
+				}
+
+				if flags.KeepFront && window.ID == 1 {
+					continue
+				}
+
+				windowIDs = append(windowIDs, window.ID)
+			}
+
+			if len(windowIDs) == 0 {
+				infof(cmd, "no window to close\n")
+				return nil
+			}
+
+			if !flags.Yes {
+				if !canPrompt() {
+					return errors.New("--yes is required to close windows when not running in a terminal")
+				}
+
+				if ok, err := confirm(cmd, fmt.Sprintf("close %d windows?", len(windowIDs))); !ok || err != nil {
+					return err
+				}
+			}
+
+			// Close the highest ids first, so that closing a window does not shift the ids of the remaining ones.
+			sort.Sort(sort.Reverse(sort.IntSlice(windowIDs)))
+			for _, windowID := range windowIDs {
+				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc" to tell window %d to close`, windowID)); err != nil {
+					return err
+				}
+			}
+
+			infof(cmd, "closed %d windows\n", len(windowIDs))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.Incognito, "incognito", false, "only close the incognito windows")
+	cmd.Flags().BoolVar(&flags.Normal, "normal", false, "only close the windows that are not incognito")
+	cmd.MarkFlagsMutuallyExclusive("incognito", "normal")
+	cmd.Flags().BoolVar(&flags.KeepFront, "keep-front", false, "leave the front window open")
+	cmd.Flags().BoolVarP(&flags.Yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// The list scripts are only run once per invocation, even when several completion functions need them.
+var (
+	listWindowsOnce = sync.OnceValues(listWindows)
+	listTabsOnce    = sync.OnceValues(listTabs)
+)
+
+// onlyFirstArg restricts a completion function to the first positional argument.
+func onlyFirstArg(fn completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// completeWindowIDs completes the ids of the open windows, using their title as description.
+func completeWindowIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	windows, err := listWindowsOnce()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, window := range windows {
+		id := strconv.Itoa(window.ID)
+		if slices.Contains(args, id) {
+			continue
+		}
+
+		completions = append(completions, fmt.Sprintf("%s\t%s", id, window.Title))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTabIndexes completes the ids of the tabs of the window selected by
+// the --window flag, which are relative to that window.
+func completeTabIndexes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	tabs, err := listTabsOnce()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	windowID, _ := cmd.Flags().GetInt("window")
+	if windowID == 0 {
+		windowID = 1
+	}
+
+	var completions []string
+	for _, tab := range tabs {
+		index := strconv.Itoa(tab.Index)
+		if tab.WindowID != windowID || slices.Contains(args, index) {
+			continue
+		}
+
+		completions = append(completions, fmt.Sprintf("%s\t%s", index, tab.Title))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTabIDs completes the arc ids of the tabs of every window.
+func completeTabIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	tabs, err := listTabsOnce()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, tab := range tabs {
+		completions = append(completions, fmt.Sprintf("%s\t%s", tab.ID, tab.Title))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles completes the names of the profiles, using their directory as description.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := listProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, profile := range profiles {
+		completions = append(completions, fmt.Sprintf("%s\t%s", profile.Name, profile.Directory))
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// flagEnvVars maps the flags that can also be set from the environment to their variable.
+var flagEnvVars = map[string]string{
+	"app-name":            "ARC_APP_NAME",
+	"applescript-timeout": "ARC_APPLESCRIPT_TIMEOUT",
+}
+
+// configPath returns the path of the config file, in $XDG_CONFIG_HOME/arc or ~/.config/arc.
+func configPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+
+	return filepath.Join(configHome, "arc", "config.yaml")
+}
+
+// stateDir returns the directory keeping the state of arc, in $XDG_STATE_HOME/arc or ~/.local/state/arc.
+func stateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(os.Getenv("HOME"), ".local", "state")
+	}
+
+	return filepath.Join(stateHome, "arc")
+}
+
+// readConfig reads the config file, which is optional.
+func readConfig() (map[string]any, error) {
+	path := configPath()
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the config file: %w", err)
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// urlTemplates returns the url templates of the templates key of the config file, by name.
+func urlTemplates() (map[string]string, error) {
+	config, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]string)
+	values, ok := config["templates"].(map[string]any)
+	if !ok && config["templates"] != nil {
+		return nil, fmt.Errorf("invalid templates in %s: expected a mapping of names to urls", configPath())
+	}
+
+	for name, value := range values {
+		template, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid template %s in %s: expected a url", name, configPath())
+		}
+		templates[name] = template
+	}
+
+	return templates, nil
+}
+
+// applyConfig sets the persistent flags of the root command from the keys of the config file, named
+// after the flags. Flags set on the command line, or through their environment variable, are left
+// untouched. The flags of the commands themselves cannot be set, as the same name means different
+// things to different commands.
+func applyConfig(cmd *cobra.Command) error {
+	config, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	for key, value := range config {
+		if key == "templates" {
+			continue
+		}
+
+		flag := cmd.Root().PersistentFlags().Lookup(key)
+		if flag == nil {
+			cmd.PrintErrf("warning: unknown key %s in %s\n", key, configPath())
+			continue
+		}
+
+		if env, ok := flagEnvVars[key]; ok && os.Getenv(env) != "" {
+			continue
+		}
+
+		if flag.Changed {
+			continue
+		}
+
+		if err := setFlagFromConfig(flag, value); err != nil {
+			return fmt.Errorf("invalid value for %s in %s: %w", key, configPath(), err)
+		}
+	}
+
+	return nil
+}
+
+func setFlagFromConfig(flag *pflag.Flag, value any) error {
+	values, ok := value.([]any)
+	if !ok {
+		return flag.Value.Set(fmt.Sprint(value))
+	}
+
+	var items []string
+	for _, item := range values {
+		items = append(items, fmt.Sprint(item))
+	}
+
+	return flag.Value.Set(strings.Join(items, ","))
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck is the result of one of the checks of the doctor command.
+type doctorCheck struct {
+	Name     string
+	Status   string
+	Hint     string
+	Critical bool
+}
+
+func NewCmdDoctor() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that arc can control Arc",
+		Long: `Check that arc can control Arc: that Arc is installed and running, that the terminal is allowed to
+control it (automation permission), to use ui scripting (accessibility permission), and that Arc
+runs javascript from apple events.
+
+The command fails if Arc cannot be controlled at all. Missing accessibility or javascript
+permissions only disable the commands that need them, so they are reported as warnings.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checks := runDoctorChecks()
+
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			failed := false
+			printer.AddHeader([]string{"Check", "Status", "Hint"})
+			for _, check := range checks {
+				printer.AddField(check.Name)
+				printer.AddField(check.Status)
+				printer.AddField(check.Hint)
+				printer.EndRow()
+
+				if check.Status == "fail" && check.Critical {
+					failed = true
+				}
+			}
+
+			if err := printer.Render(); err != nil {
+				return err
+			}
+
+			if failed {
+				return errors.New("arc cannot control Arc, see the hints above")
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// runDoctorChecks runs the checks in order, skipping the ones depending on a failed check.
+func runDoctorChecks() []doctorCheck {
+	installed := doctorCheck{Name: "Arc is installed", Status: "ok", Critical: true}
+	if err := checkInstalled(); err != nil {
+		installed.Status = "fail"
+		installed.Hint = "download Arc from https://arc.net, or set --app-name to the name of the application"
+	}
+
+	running := doctorCheck{Name: "Arc is running", Status: "ok", Critical: true}
+	automation := doctorCheck{Name: "Automation permission", Status: "ok", Critical: true}
+	accessibility := doctorCheck{Name: "Accessibility permission", Status: "ok"}
+	javascript := doctorCheck{Name: "JavaScript from Apple Events", Status: "ok"}
+	checks := []doctorCheck{installed, running, automation, accessibility, javascript}
+
+	skip := func(checks []doctorCheck) {
+		for i := range checks {
+			checks[i].Status = "skip"
+		}
+	}
+
+	if installed.Status != "ok" {
+		skip(checks[1:])
+		return checks
+	}
+
+	// the check must not launch Arc, so it bypasses runApplescript
+	output, err := execApplescript(`application "Arc" is running`)
+	if err != nil || strings.TrimSpace(string(output)) != "true" {
+		checks[1].Status = "fail"
+		checks[1].Hint = "start Arc, the permissions can only be checked while it is running"
+		skip(checks[2:])
+		return checks
+	}
+
+	output, err = execApplescript(`tell application "Arc" to count windows`)
+	if err != nil {
+		checks[2].Status = "fail"
+		checks[2].Hint = "allow your terminal to control Arc in System Settings > Privacy & Security > Automation"
+		skip(checks[3:])
+		return checks
+	}
+
+	if _, err := execApplescript(`tell application "System Events" to tell process "Arc" to get name of menu bar 1`); err != nil {
+		var applescriptError *AppleScriptError
+		checks[3].Status = "warn"
+		checks[3].Hint = "grant the accessibility permission to your terminal in System Settings > Privacy & Security > Accessibility, needed by the commands using ui scripting"
+		if !errors.As(err, &applescriptError) || !applescriptError.AccessibilityDenied() {
+			checks[3].Hint = "allow your terminal to control System Events in System Settings > Privacy & Security > Automation"
+		}
+	}
+
+	if strings.TrimSpace(string(output)) == "0" {
+		checks[4].Status = "skip"
+		checks[4].Hint = "open a window in Arc to check it"
+	} else if _, err := executeJavascript(0, 0, "1"); err != nil {
+		checks[4].Status = "warn"
+		checks[4].Hint = "enable View > Developer > Allow JavaScript from Apple Events in Arc, needed by the commands running javascript"
+	}
+
+	return checks
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	sb "github.com/huandu/go-sqlbuilder"
+	"github.com/spf13/cobra"
+)
+
+type Download struct {
+	ID        int    `json:"id"`
+	Path      string `json:"path"`
+	URL       string `json:"url"`
+	StartedAt string `json:"startedAt"`
+	State     string `json:"state"`
+}
+
+// downloadStates maps the states stored by chromium in the downloads table to their name.
+var downloadStates = map[int]string{
+	0: "in_progress",
+	1: "complete",
+	2: "cancelled",
+	3: "interrupted",
+	4: "interrupted",
+}
+
+func NewCmdDownloads() *cobra.Command {
+	var flags struct {
+		since   time.Duration
+		limit   int
+		json    bool
+		profile string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "downloads",
+		Short: "List recent downloads",
+		Long: `List recent downloads, most recent first.
+
+Downloads are read from the history database, like the history command, so they can be listed while
+Arc is running. The url is the last one of the redirection chain of the download.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := profileHistoryPath(flags.profile)
+			if err != nil {
+				return err
+			}
+
+			db, cleanup, err := openHistoryDB(path)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			sb := sb.NewSelectBuilder()
+			sb.Select(
+				"id",
+				"target_path",
+				"(SELECT url FROM downloads_url_chains WHERE downloads_url_chains.id = downloads.id ORDER BY chain_index DESC LIMIT 1)",
+				sb.As("datetime(start_time / 1000000 + (strftime('%s', '1601-01-01')), 'unixepoch', 'localtime')", "startedAt"),
+				"state",
+			)
+			sb.From("downloads")
+			sb.OrderBy("start_time DESC")
+
+			if flags.limit > 0 {
+				sb.Limit(flags.limit)
+			}
+
+			if flags.since > 0 {
+				sb.Where(sb.GreaterEqualThan("start_time", chromiumTime(time.Now().Add(-flags.since))))
+			}
+
+			sql, sqlArgs := sb.Build()
+			rows, err := db.Query(sql, sqlArgs...)
+			if err != nil {
+				return historyQueryError(err)
+			}
+			defer rows.Close()
+
+			var downloads []Download
+			for rows.Next() {
+				var download Download
+				var url *string
+				var state int
+				if err := rows.Scan(&download.ID, &download.Path, &url, &download.StartedAt, &state); err != nil {
+					return fmt.Errorf("failed to scan: %w", err)
+				}
+
+				if url != nil {
+					download.URL = *url
+				}
+
+				download.State = downloadStates[state]
+				if download.State == "" {
+					download.State = "unknown"
+				}
+				downloads = append(downloads, download)
+			}
+
+			if err := rows.Err(); err != nil {
+				return historyQueryError(err)
+			}
+
+			if flags.json {
+				return printJSON(cmd.OutOrStdout(), downloads)
+			}
+
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			printer.AddHeader([]string{"Path", "URL", "StartedAt", "State"})
+			for _, download := range downloads {
+				printer.AddField(download.Path)
+				printer.AddField(download.URL)
+				printer.AddField(download.StartedAt)
+				printer.AddField(download.State)
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	cmd.Flags().IntVarP(&flags.limit, "limit", "l", 100, "maximum number of downloads to show")
+	cmd.Flags().DurationVar(&flags.since, "since", 0, "only show downloads started within this duration, e.g. 24h")
+	cmd.Flags().BoolVar(&flags.json, "json", false, "output as json")
+	cmd.Flags().StringVar(&flags.profile, "profile", "", "read the downloads of this profile, by name or directory (defaults to the default profile)")
+	cmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdExec() *cobra.Command {
+	var flags struct {
+		File   string
+		Window int
+		Tab    int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "exec [javascript]",
+		Short: "Execute javascript in a tab and print its result",
+		Long: `Execute javascript in a tab and print its result.
+
+The javascript is read from the first argument, from --file, or from stdin. Arc only runs it when
+"Allow JavaScript from Apple Events" is enabled in View > Developer.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && cmd.Flags().Changed("file") {
+				return fmt.Errorf("javascript cannot be given both as argument and with --file")
+			}
+
+			var inline string
+			if len(args) > 0 {
+				inline = args[0]
+			}
+
+			javascript, err := readJavascript(cmd.InOrStdin(), inline, flags.File)
+			if err != nil {
+				return err
+			}
+
+			output, err := executeJavascript(flags.Window, flags.Tab, javascript)
+			if err != nil {
+				return err
+			}
+
+			if _, err := cmd.OutOrStdout().Write(output); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.File, "file", "f", "", "file containing the javascript to evaluate")
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.Tab, "tab", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("tab", completeTabIndexes)
+	return cmd
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdFocusMode() *cobra.Command {
+	var flags struct {
+		On     bool
+		Off    bool
+		Toggle bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "focus-mode",
+		Short: "Hide everything but the page of the front window",
+		Long: `Turn focus mode on or off for the front window, or toggle it.
+
+Focus mode hides the sidebar, like View > Hide Sidebar (Cmd+S), and switches the window to fullscreen,
+leaving only the page on screen, with the command bar still available through Cmd+T. Turning it off
+shows the sidebar and exits fullscreen.
+
+The sidebar is shown or hidden with Cmd+S and the window switched to fullscreen through System Events.
+This uses ui scripting (accessibility permission, see arc doctor). Whether the sidebar is shown is read
+from the View menu; when it cannot be, the sidebar is left as is. With --toggle, focus mode is
+considered on when the window is fullscreen.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !flags.On && !flags.Off && !flags.Toggle {
+				return fmt.Errorf("one of --on, --off or --toggle must be set")
+			}
+
+			on := flags.On
+			if flags.Toggle {
+				output, err := runApplescript(`tell application "System Events" to tell process "Arc"
+	return value of attribute "AXFullScreen" of front window
+end tell`)
+				if err != nil {
+					return uiScriptingError(err)
+				}
+
+				// focus mode is off as long as the window is not fullscreen
+				on = strings.TrimSpace(string(output)) != "true"
+			}
+
+			action := "show"
+			if on {
+				action = "hide"
+			}
+
+			state, err := setSidebar(action)
+			if err != nil {
+				return err
+			}
+
+			if state == "unknown" {
+				cmd.PrintErrf("warning: could not read whether the sidebar is shown, it was left unchanged\n")
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "System Events" to tell process "Arc"
+	set value of attribute "AXFullScreen" of front window to %t
+end tell`, on)); err != nil {
+				return uiScriptingError(err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.On, "on", false, "turn focus mode on")
+	cmd.Flags().BoolVar(&flags.Off, "off", false, "turn focus mode off")
+	cmd.Flags().BoolVar(&flags.Toggle, "toggle", false, "turn focus mode on if it is off, and off otherwise")
+	cmd.MarkFlagsMutuallyExclusive("on", "off", "toggle")
+	return cmd
+}
@@ -2,21 +2,41 @@ package main
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/cli/go-gh/v2/pkg/tableprinter"
 	sb "github.com/huandu/go-sqlbuilder"
-	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 	_ "modernc.org/sqlite"
 )
 
-var historyPath = filepath.Join(os.Getenv("HOME"), "Library", "Application Support", "Arc", "User Data", "Default", "History")
+var historyPath = filepath.Join(userDataPath, "Default", "History")
+
+// profileHistoryPath returns the path of the history database of a profile, or of the default
+// profile when name is empty.
+func profileHistoryPath(name string) (string, error) {
+	if name == "" {
+		return historyPath, nil
+	}
+
+	profile, err := findProfile(name)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(userDataPath, profile.Directory, "History")
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("profile %q has no history yet", profile.Name)
+	}
+
+	return path, nil
+}
 
 type HistoryEntry struct {
 	ID            int    `db:"id" json:"id"`
@@ -25,11 +45,63 @@ type HistoryEntry struct {
 	LastVisitedAt string `db:"lastVisitedAt" json:"lastVisitedAt"`
 }
 
+// openHistoryDB opens a read-only copy of a chromium history database. Arc
+// keeps the database locked while it is running, so it cannot be read in place.
+func openHistoryDB(path string) (*sql.DB, func(), error) {
+	dbFile, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open db file: %w", err)
+	}
+	defer dbFile.Close()
+
+	tempfile, err := os.CreateTemp("", "arc-history-*.sqlite")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create tempfile: %w", err)
+	}
+	defer tempfile.Close()
+
+	cleanup := func() {
+		os.Remove(tempfile.Name())
+	}
+
+	if _, err := io.Copy(tempfile, dbFile); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to copy db file: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", tempfile.Name()))
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to open db: %w", err)
+	}
+
+	return db, func() {
+		db.Close()
+		cleanup()
+	}, nil
+}
+
+// historyQueryError explains the failures caused by copying the database while Arc was writing to it.
+func historyQueryError(err error) error {
+	if strings.Contains(err.Error(), "database is locked") || strings.Contains(err.Error(), "malformed") {
+		return fmt.Errorf("failed to query: the history database is being written by Arc, try again in a few seconds: %w", err)
+	}
+
+	return fmt.Errorf("failed to query: %w", err)
+}
+
+// chromiumTime converts a time to the number of microseconds since 1601-01-01 used by chromium databases.
+func chromiumTime(t time.Time) int64 {
+	return (t.Unix() + 11644473600) * 1000000
+}
+
 func NewCmdHistory() *cobra.Command {
 	var flags struct {
-		query string
-		limit int
-		json  bool
+		search  string
+		since   time.Duration
+		limit   int
+		json    bool
+		profile string
 	}
 
 	cmd := &cobra.Command{
@@ -37,26 +109,16 @@ func NewCmdHistory() *cobra.Command {
 		Short: "Search history",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			dbFile, err := os.Open(historyPath)
-			if err != nil {
-				return fmt.Errorf("failed to open db file: %w", err)
-			}
-			defer dbFile.Close()
-
-			tempfile, err := os.CreateTemp("", "arc-history-*.sqlite")
+			path, err := profileHistoryPath(flags.profile)
 			if err != nil {
-				return fmt.Errorf("failed to create tempfile: %w", err)
+				return err
 			}
-			defer os.Remove(tempfile.Name())
 
-			if _, err := io.Copy(tempfile, dbFile); err != nil {
-				return fmt.Errorf("failed to copy db file: %w", err)
-			}
-
-			db, err := sql.Open("sqlite", tempfile.Name())
+			db, cleanup, err := openHistoryDB(path)
 			if err != nil {
-				return fmt.Errorf("failed to open db: %w", err)
+				return err
 			}
+			defer cleanup()
 
 			sb := sb.NewSelectBuilder()
 			sb.Select("id", "url", "title", sb.As("datetime(last_visit_time / 1000000 + (strftime('%s', '1601-01-01')), 'unixepoch', 'localtime')", "lastVisitedAt"))
@@ -68,17 +130,21 @@ func NewCmdHistory() *cobra.Command {
 				sb.Limit(flags.limit)
 			}
 
-			if len(flags.query) > 0 {
+			if len(flags.search) > 0 {
 				sb.Where(sb.Or(
-					sb.Like("url", fmt.Sprintf("%%%s%%", flags.query)),
-					sb.Like("title", fmt.Sprintf("%%%s%%", flags.query)),
+					sb.Like("url", fmt.Sprintf("%%%s%%", flags.search)),
+					sb.Like("title", fmt.Sprintf("%%%s%%", flags.search)),
 				))
 			}
 
+			if flags.since > 0 {
+				sb.Where(sb.GreaterEqualThan("last_visit_time", chromiumTime(time.Now().Add(-flags.since))))
+			}
+
 			sql, sqlArgs := sb.Build()
 			rows, err := db.Query(sql, sqlArgs...)
 			if err != nil {
-				return fmt.Errorf("failed to query: %w", err)
+				return historyQueryError(err)
 			}
 			defer rows.Close()
 
@@ -92,22 +158,12 @@ func NewCmdHistory() *cobra.Command {
 			}
 
 			if flags.json {
-				encoder := json.NewEncoder(os.Stdout)
-				encoder.SetIndent("", "  ")
-				encoder.SetEscapeHTML(false)
-				return encoder.Encode(entries)
+				return printJSON(cmd.OutOrStdout(), entries)
 			}
 
-			var printer tableprinter.TablePrinter
-			if !isatty.IsTerminal(os.Stdout.Fd()) {
-				printer = tableprinter.New(os.Stdout, false, 0)
-			} else {
-				w, _, err := term.GetSize(int(os.Stdout.Fd()))
-				if err != nil {
-					return err
-				}
-
-				printer = tableprinter.New(os.Stdout, true, w)
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
 			}
 
 			printer.AddHeader([]string{"URL", "Title", "LastVisitedAt"})
@@ -122,9 +178,14 @@ func NewCmdHistory() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().IntVarP(&flags.limit, "limit", "l", 100, "limit")
-	cmd.Flags().StringVarP(&flags.query, "query", "q", "", "query")
+	cmd.Flags().IntVarP(&flags.limit, "limit", "l", 100, "maximum number of entries to show")
+	cmd.Flags().StringVarP(&flags.search, "search", "s", "", "only show entries whose title or url contains this term")
+	cmd.Flags().StringVar(&flags.search, "query", "", "only show entries whose title or url contains this term")
+	cmd.Flags().MarkDeprecated("query", "use --search instead")
+	cmd.Flags().DurationVar(&flags.since, "since", 0, "only show entries visited within this duration, e.g. 24h")
 	cmd.Flags().BoolVar(&flags.json, "json", false, "output as json")
+	cmd.Flags().StringVar(&flags.profile, "profile", "", "read the history of this profile, by name or directory (defaults to the default profile)")
+	cmd.RegisterFlagCompletionFunc("profile", completeProfiles)
 
 	return cmd
 }
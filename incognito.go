@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdIncognito() *cobra.Command {
+	var flags struct {
+		Keep bool
+		focusFlags
+		waitFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:   "incognito [url]",
+		Short: "Create a new incognito window",
+		Long:  "Create a new incognito window, like `arc window create --incognito`.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return windowCreate(cmd, true, flags.focusFlags, "", flags.Keep, flags.waitFlags, args)
+		},
+	}
+
+	addFocusFlags(cmd, &flags.focusFlags)
+	addWaitFlags(cmd, &flags.waitFlags)
+	addKeepExistingFlag(cmd, &flags.Keep)
+	cmd.MarkFlagsMutuallyExclusive("wait", "focus")
+	return cmd
+}
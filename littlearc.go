@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdLittleArc() *cobra.Command {
+	var flags struct {
+		waitFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:   "little-arc <url>",
+		Short: "Open a url in a little arc window",
+		Long: `Open a url in a little arc window, like tab create --little.
+
+Arc opens a little arc window when a tab is created on the application itself rather than on one of its
+windows, so no ui scripting, nor the accessibility permission, is needed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := runApplescript(tabCreateScript(normalizeURL(args[0]), 0, 0, false, true, false)); err != nil {
+				return err
+			}
+
+			if err := activateArc(); err != nil {
+				return err
+			}
+
+			return flags.waitForTab(cmd, 0, 0)
+		},
+	}
+
+	addWaitFlags(cmd, &flags.waitFlags)
+	return cmd
+}
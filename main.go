@@ -1,20 +1,256 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 )
 
-func runApplescript(code string) ([]byte, error) {
-	output, err := exec.Command("osascript", "-e", code).Output()
+var applescriptTimeout = defaultApplescriptTimeout()
+
+func defaultApplescriptTimeout() time.Duration {
+	if value, ok := os.LookupEnv("ARC_APPLESCRIPT_TIMEOUT"); ok {
+		if timeout, err := time.ParseDuration(value); err == nil {
+			return timeout
+		}
+	}
+
+	return 30 * time.Second
+}
+
+// AppleScriptError is returned when osascript exits with a non-zero status.
+type AppleScriptError struct {
+	Script   string
+	ExitCode int
+	// Number is the AppleScript error number reported by osascript, if any.
+	Number int
+	Stderr string
+}
+
+var applescriptErrorNumberRegexp = regexp.MustCompile(`\((-?\d+)\)\s*$`)
+
+func newAppleScriptError(script string, exitError *exec.ExitError) *AppleScriptError {
+	err := &AppleScriptError{
+		Script:   script,
+		ExitCode: exitError.ExitCode(),
+		Stderr:   strings.TrimSpace(string(exitError.Stderr)),
+	}
+
+	if matches := applescriptErrorNumberRegexp.FindStringSubmatch(err.Stderr); matches != nil {
+		err.Number, _ = strconv.Atoi(matches[1])
+	}
+
+	return err
+}
+
+func (e *AppleScriptError) Error() string {
+	// Use the first meaningful line of the script as context, skipping shebangs and comments.
+	var context string
+	for _, line := range strings.Split(e.Script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		context = line
+		break
+	}
+
+	return fmt.Sprintf("applescript `%s` failed: %s", context, e.Stderr)
+}
+
+// NotRunning reports whether the script failed because Arc is not running.
+func (e *AppleScriptError) NotRunning() bool {
+	return e.Number == -600
+}
+
+var appName = defaultAppName()
+
+func defaultAppName() string {
+	if value := os.Getenv("ARC_APP_NAME"); value != "" {
+		return value
+	}
+
+	return "Arc"
+}
+
+// targetApp points the application and process references of a script to the
+// application selected by --app-name. Values interpolated in scripts are always
+// escaped, so they cannot contain an unescaped reference.
+func targetApp(code string) string {
+	if appName == "Arc" {
+		return code
+	}
+
+	name := fmt.Sprintf(`"%s"`, escapeApplescript(appName))
+	return strings.NewReplacer(
+		`application "Arc"`, "application "+name,
+		`process "Arc"`, "process "+name,
+	).Replace(code)
+}
+
+// AccessibilityDenied reports whether the script failed because sending ui events through
+// System Events requires the accessibility permission.
+func (e *AppleScriptError) AccessibilityDenied() bool {
+	return e.Number == -1719 || e.Number == -25211 || e.Number == 1002
+}
+
+// uiScriptingError adds remediation hints to errors caused by a missing accessibility permission.
+func uiScriptingError(err error) error {
+	var applescriptError *AppleScriptError
+	if errors.As(err, &applescriptError) && applescriptError.AccessibilityDenied() {
+		return fmt.Errorf("%w\nthis command uses ui scripting: grant the accessibility permission to your terminal in System Settings > Privacy & Security > Accessibility", err)
+	}
+
+	return err
+}
+
+// logger logs the applescripts being run, it is enabled by --verbose.
+var logger = log.New(io.Discard, "", 0)
+
+// maxLoggedScript is the number of characters of a script logged before it is truncated.
+const maxLoggedScript = 500
+
+func logApplescript(code string, args []string, output []byte) {
+	script := []rune(targetApp(code))
+	if len(script) > maxLoggedScript {
+		script = append(script[:maxLoggedScript], []rune("…")...)
+	}
+
+	logger.Printf("applescript: %s", string(script))
+	if len(args) > 0 {
+		logger.Printf("arguments: %s", strings.Join(args, " "))
+	}
+	logger.Printf("output: %s", output)
+}
+
+// checkInstalled verifies once that the application controlled by arc is installed, so that
+// scripts targeting it do not fail with a cryptic error. It uses open -R, which locates the
+// application bundle without launching it.
+var checkInstalled = sync.OnceValue(func() error {
+	if err := exec.Command("open", "-Ra", appName).Run(); err != nil {
+		return fmt.Errorf("%s does not appear to be installed: download it from https://arc.net, or use --app-name if the application was renamed", appName)
+	}
+
+	return nil
+})
+
+// noActivate prevents commands from bringing Arc to the front, except the ones relying on ui
+// scripting, which need Arc to be frontmost to receive the keystrokes and clicks.
+var noActivate bool
+
+// activateStatement returns the applescript statement bringing Arc to the front, or an empty
+// statement when --no-activate is set.
+func activateStatement() string {
+	if noActivate {
+		return ""
+	}
+
+	return "activate"
+}
+
+// activateArc brings Arc to the front, unless --no-activate is set.
+func activateArc() error {
+	if noActivate {
+		return nil
+	}
+
+	_, err := runApplescript(`tell application "Arc" to activate`)
+	return err
+}
+
+// noLaunch prevents launching Arc when it is not running, runApplescript fails instead.
+var noLaunch bool
+
+// arcWasRunning reports whether Arc was already running before arc ran its first script.
+var arcWasRunning = true
+
+var errNotRunning = errors.New("Arc is not running, start it or remove --no-launch")
+
+// launchTimeout is how long to wait for Arc to open its first window after launching it.
+const launchTimeout = 10 * time.Second
+
+// ensureRunning launches Arc once if it is not running, and waits until it has opened a window.
+var ensureRunning = sync.OnceValue(func() error {
+	output, err := execApplescript(`application "Arc" is running`)
 	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(string(output)) == "true" {
+		return nil
+	}
+
+	arcWasRunning = false
+	if noLaunch {
+		return errNotRunning
+	}
+
+	launch := `tell application "Arc" to activate`
+	if noActivate {
+		launch = `tell application "Arc" to launch`
+	}
+
+	if _, err := execApplescript(launch); err != nil {
+		return err
+	}
+
+	// arc answers with 0 windows until its startup windows are restored
+	for deadline := time.Now().Add(launchTimeout); time.Now().Before(deadline); time.Sleep(250 * time.Millisecond) {
+		output, err := execApplescript(`tell application "Arc" to count windows`)
+		if err == nil && strings.TrimSpace(string(output)) != "0" {
+			break
+		}
+	}
+
+	return nil
+})
+
+// runApplescript runs the given script, passing args to its run handler. Arc is launched first
+// if it is not running, unless --no-launch is set.
+func runApplescript(code string, args ...string) ([]byte, error) {
+	if err := checkInstalled(); err != nil {
+		return nil, err
+	}
+
+	if err := ensureRunning(); err != nil {
+		return nil, err
+	}
+
+	return execApplescript(code, args...)
+}
+
+// execApplescript runs the given script without checking that Arc is installed and running.
+func execApplescript(code string, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if applescriptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, applescriptTimeout)
+		defer cancel()
+	}
+
+	output, err := exec.CommandContext(ctx, "osascript", append([]string{"-e", targetApp(code)}, args...)...).Output()
+	logApplescript(code, args, output)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("applescript timed out after %s", applescriptTimeout)
+		}
+
 		if exitError, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("%s", exitError.Stderr)
+			return nil, newAppleScriptError(code, exitError)
 		}
 
 		return nil, err
@@ -23,21 +259,71 @@ func runApplescript(code string) ([]byte, error) {
 	return output, nil
 }
 
+func escapeApplescript(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return s
+}
+
+func copyToClipboard(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+func readClipboard() (string, error) {
+	output, err := exec.Command("pbpaste").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the clipboard: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// Build metadata, injected with -ldflags "-X main.version=... -X main.commit=... -X main.date=...".
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
 func NewCmdVersion() *cobra.Command {
+	var flags struct {
+		Json bool
+		App  bool
+	}
+
 	cmd := &cobra.Command{
 		Use:   "version",
-		Short: "Print the version of Arc",
+		Short: "Print the version of arc",
+		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output, err := runApplescript(`tell application "Arc" to return version`)
-			if err != nil {
-				return err
+			if flags.App {
+				output, err := runApplescript(`tell application "Arc" to return version`)
+				if err != nil {
+					return err
+				}
+
+				cmd.Print(string(output))
+				return nil
+			}
+
+			if flags.Json {
+				return printJSON(cmd.OutOrStdout(), map[string]string{
+					"version": version,
+					"commit":  commit,
+					"date":    date,
+				})
 			}
 
-			cmd.Print(string(output))
+			fmt.Fprintf(cmd.OutOrStdout(), "arc %s\ncommit: %s\nbuilt: %s\n", version, commit, date)
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&flags.Json, "json", false, "output as json")
+	cmd.Flags().BoolVar(&flags.App, "app", false, "print the version of the Arc application instead")
+	cmd.MarkFlagsMutuallyExclusive("json", "app")
 	return cmd
 }
 
@@ -94,21 +380,148 @@ func NewDocCmd() *cobra.Command {
 	return docCmd
 }
 
+// jsonOutput reports whether the user asked for json output, either through the --json or the --output flag.
+func jsonOutput(cmd *cobra.Command) bool {
+	if flag := cmd.Flags().Lookup("json"); flag != nil && flag.Value.String() == "true" {
+		return true
+	}
+
+	if flag := cmd.Flags().Lookup("output"); flag != nil && (flag.Value.String() == "json" || flag.Value.String() == "jsonl") {
+		return true
+	}
+
+	return false
+}
+
+// verbose enables the logger.
+var verbose bool
+
+// quiet silences the informational messages printed by the commands.
+var quiet bool
+
+// infof prints an informational message, unless --quiet was set.
+func infof(cmd *cobra.Command, format string, args ...any) {
+	if quiet {
+		return
+	}
+
+	cmd.Printf(format, args...)
+}
+
+// NotFoundError reports that a tab, window, space or text looked up by a command does not exist.
+type NotFoundError struct {
+	message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.message
+}
+
+func notFoundf(format string, args ...any) error {
+	return &NotFoundError{message: fmt.Sprintf(format, args...)}
+}
+
+// Exit codes, documented in the readme.
+const (
+	exitError      = 1
+	exitNotFound   = 2
+	exitNotRunning = 3
+)
+
+// exitCode returns the exit code of a failed command.
+func exitCode(err error) int {
+	var notFoundError *NotFoundError
+	if errors.As(err, &notFoundError) {
+		return exitNotFound
+	}
+
+	var applescriptError *AppleScriptError
+	if errors.Is(err, errNotRunning) || (errors.As(err, &applescriptError) && applescriptError.NotRunning()) {
+		return exitNotRunning
+	}
+
+	return exitError
+}
+
+func printError(cmd *cobra.Command, err error) {
+	if !jsonOutput(cmd) {
+		cmd.PrintErrln(cmd.ErrPrefix(), err.Error())
+		return
+	}
+
+	payload := struct {
+		Error    string `json:"error"`
+		ExitCode int    `json:"exitCode"`
+	}{
+		Error:    err.Error(),
+		ExitCode: exitCode(err),
+	}
+
+	var applescriptError *AppleScriptError
+	if errors.As(err, &applescriptError) {
+		payload.Error = applescriptError.Stderr
+	}
+
+	encoder := json.NewEncoder(cmd.ErrOrStderr())
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(payload)
+}
+
 func main() {
 	cmd := cobra.Command{
 		Use:          "arc",
 		Short:        "Arc Companion CLI",
+		Version:      version,
 		SilenceUsage: true,
 	}
+	cmd.SetVersionTemplate(fmt.Sprintf("arc %s (%s, built %s)\n", version, commit, date))
+
+	cmd.PersistentFlags().StringVar(&appName, "app-name", appName, "name of the Arc application to control (env: ARC_APP_NAME)")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "do not print informational messages")
+	cmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "do not style the output, like setting NO_COLOR (env: NO_COLOR)")
+	cmd.PersistentFlags().BoolVar(&compact, "compact", false, "print json on a single line instead of indenting it")
+	cmd.PersistentFlags().BoolVar(&noActivate, "no-activate", false, "do not bring Arc to the front, except for the commands using ui scripting")
+	cmd.PersistentFlags().BoolVar(&noLaunch, "no-launch", false, "fail instead of launching Arc when it is not running")
+	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log the applescripts being run and their output to stderr")
+	cmd.PersistentFlags().DurationVar(&applescriptTimeout, "applescript-timeout", applescriptTimeout, "timeout of each applescript call, 0 to disable (env: ARC_APPLESCRIPT_TIMEOUT)")
 
 	cmd.AddCommand(NewCmdTab())
 	cmd.AddCommand(NewCmdSpace())
 	cmd.AddCommand(NewCmdWindow())
 	cmd.AddCommand(NewCmdHistory())
+	cmd.AddCommand(NewCmdDownloads())
+	cmd.AddCommand(NewCmdOpen())
+	cmd.AddCommand(NewCmdIncognito())
+	cmd.AddCommand(NewCmdLittleArc())
+	cmd.AddCommand(NewCmdCloseAll())
+	cmd.AddCommand(NewCmdSession())
+	cmd.AddCommand(NewCmdProfile())
+	cmd.AddCommand(NewCmdExec())
+	cmd.AddCommand(NewCmdActive())
+	cmd.AddCommand(NewCmdWatch())
+	cmd.AddCommand(NewCmdScreenshot())
+	cmd.AddCommand(NewCmdFocusMode())
+	cmd.AddCommand(NewCmdSidebar())
+	cmd.AddCommand(NewCmdTheme())
+	cmd.AddCommand(NewCmdDoctor())
 	cmd.AddCommand(NewCmdVersion())
 	cmd.AddCommand(NewDocCmd())
 
-	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyConfig(cmd); err != nil {
+			return err
+		}
+
+		if verbose {
+			logger.SetOutput(os.Stderr)
+		}
+
+		return nil
+	}
+
+	cmd.SilenceErrors = true
+	if c, err := cmd.ExecuteC(); err != nil {
+		printError(c, err)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// readURLs reads one url per line, skipping blank lines and lines starting with #.
+func readURLs(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		urls = append(urls, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// urlsFromClipboard returns the urls copied to the clipboard, one per line, and fails if any of them
+// does not look like a url.
+func urlsFromClipboard() ([]string, error) {
+	content, err := readClipboard()
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := readURLs(strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("the clipboard is empty")
+	}
+
+	for _, url := range urls {
+		if strings.ContainsAny(url, " \t") || (!urlSchemeRegexp.MatchString(url) && !strings.Contains(url, ".")) {
+			return nil, fmt.Errorf("the clipboard does not contain a url: %q", url)
+		}
+	}
+
+	return urls, nil
+}
+
+// urlsFromArgsOrClipboard returns the urls given as arguments, or copied to the clipboard when
+// fromClipboard is set, or read from stdin when it is not a terminal.
+func urlsFromArgsOrClipboard(cmd *cobra.Command, args []string, fromClipboard bool) ([]string, error) {
+	if len(args) == 0 && fromClipboard {
+		return urlsFromClipboard()
+	}
+
+	return urlsFromArgsOrStdin(cmd, args)
+}
+
+// urlsFromArgsOrStdin returns the urls given as arguments, or read from stdin when it is not a terminal.
+func urlsFromArgsOrStdin(cmd *cobra.Command, args []string) ([]string, error) {
+	if len(args) > 0 || isatty.IsTerminal(os.Stdin.Fd()) {
+		return args, nil
+	}
+
+	return readURLs(cmd.InOrStdin())
+}
+
+func NewCmdOpen() *cobra.Command {
+	var flags struct {
+		Window    int
+		Incognito bool
+		NewWindow bool
+		Delay     time.Duration
+		Clipboard bool
+		Keep      bool
+		reuseFlags
+		waitFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:   "open <url...>",
+		Short: "Open urls in new tabs",
+		Long: `Open urls in new tabs.
+
+When no url is given and stdin is not a terminal, urls are read from stdin, one per line.
+Blank lines and lines starting with # are skipped. With --clipboard, they are read from the
+clipboard instead.
+
+With --reuse, a url already open in a tab of any window selects that tab instead of opening a new one.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			urls, err := urlsFromArgsOrClipboard(cmd, args, flags.Clipboard)
+			if err != nil {
+				return err
+			}
+
+			if len(urls) == 0 {
+				return fmt.Errorf("no url provided")
+			}
+
+			opened, reused := 0, false
+			for i, url := range urls {
+				if i > 0 {
+					time.Sleep(flags.Delay)
+				}
+
+				if reused, err = flags.focusOpenTab(url); err != nil {
+					return err
+				} else if reused {
+					continue
+				}
+
+				makeWindow := `if (count of windows) is 0 then make new window`
+				target := windowSpecifier(flags.Window)
+				if i > 0 && (flags.Incognito || flags.NewWindow) {
+					// the window was created when opening the first url
+					makeWindow = ""
+				} else if flags.Incognito {
+					makeWindow = `make new window with properties {incognito:true}`
+				} else if flags.NewWindow {
+					makeWindow = `make new window`
+				}
+
+				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+					%s
+					tell %s
+						make new tab with properties {URL:"%s"}
+					end tell
+					%s
+				end tell`, makeWindow, target, escapeApplescript(url), activateStatement())); err != nil {
+					return err
+				}
+
+				if opened == 0 && (flags.Incognito || flags.NewWindow) && !flags.Keep {
+					if err := closeStartupWindows(); err != nil {
+						return err
+					}
+				}
+				opened++
+			}
+
+			infof(cmd, "opened %d tabs\n", opened)
+			if opened < len(urls) {
+				infof(cmd, "reused %d tabs\n", len(urls)-opened)
+			}
+
+			// the last opened tab is the active one
+			target := flags.Window
+			if flags.Incognito || flags.NewWindow || reused {
+				target = 0
+			}
+
+			return flags.waitForTab(cmd, target, 0)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window to open the urls in (defaults to the front window)")
+	cmd.Flags().BoolVar(&flags.Incognito, "incognito", false, "open the urls in a new incognito window")
+	cmd.Flags().BoolVar(&flags.NewWindow, "new-window", false, "open the urls in a new window")
+	cmd.Flags().DurationVar(&flags.Delay, "delay", 0, "delay between opening two urls")
+	cmd.Flags().BoolVar(&flags.Clipboard, "clipboard", false, "open the urls copied to the clipboard when no url is given")
+	addWaitFlags(cmd, &flags.waitFlags)
+	addKeepExistingFlag(cmd, &flags.Keep)
+	addReuseFlags(cmd, &flags.reuseFlags)
+	// selecting a reused tab brings its window to the front, which changes the window the next urls open in
+	cmd.MarkFlagsMutuallyExclusive("reuse", "window")
+	cmd.MarkFlagsMutuallyExclusive("reuse", "incognito")
+	cmd.MarkFlagsMutuallyExclusive("reuse", "new-window")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.MarkFlagsMutuallyExclusive("window", "new-window")
+	cmd.MarkFlagsMutuallyExclusive("window", "incognito")
+	return cmd
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/text"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// compact is set by --compact, to print json documents on a single line.
+var compact bool
+
+// newJSONEncoder returns an encoder writing indented json, or single line json with --compact. Html
+// characters are not escaped, so that urls are printed as is.
+func newJSONEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if !compact {
+		encoder.SetIndent("", "  ")
+	}
+	encoder.SetEscapeHTML(false)
+	return encoder
+}
+
+// noColor is set by --no-color, to keep the output plain even on a terminal.
+var noColor bool
+
+// colorEnabled reports whether output may be styled, which --no-color and the NO_COLOR environment
+// variable prevent. Only the prompts are styled for now, tables are always plain.
+func colorEnabled() bool {
+	return !noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// printJSON writes a json document, see newJSONEncoder.
+func printJSON(w io.Writer, v any) error {
+	return newJSONEncoder(w).Encode(v)
+}
+
+// newTablePrinter returns a printer rendering a table fitting in width when isTTY is set, or tab
+// separated values without decoration otherwise. Fields must only be styled with
+// tableprinter.WithColor when colorEnabled reports true.
+func newTablePrinter(w io.Writer, isTTY bool, width int) tableprinter.TablePrinter {
+	return tableprinter.New(w, isTTY, width)
+}
+
+// newOutputTablePrinter returns a table printer for w, decorated and sized to the terminal when w is
+// one.
+func newOutputTablePrinter(w io.Writer) (tableprinter.TablePrinter, error) {
+	f, ok := w.(*os.File)
+	if !ok || !isatty.IsTerminal(f.Fd()) {
+		return newTablePrinter(w, false, 0), nil
+	}
+
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	return newTablePrinter(w, true, width), nil
+}
+
+// outputFlags holds the flags controlling the output of list commands.
+type outputFlags struct {
+	Output    string
+	Json      bool
+	Fields    []string
+	Format    string
+	Count     bool
+	Porcelain bool
+}
+
+// porcelainItem is implemented by the items supporting --porcelain. The columns of a row are part
+// of the stability contract documented in the README: columns may be appended, but never removed
+// or reordered.
+type porcelainItem interface {
+	porcelainRow() []string
+}
+
+func addOutputFlags(cmd *cobra.Command, flags *outputFlags) {
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", "table", "output format, one of table, json, jsonl or yaml")
+	cmd.Flags().BoolVar(&flags.Json, "json", false, "output as json")
+	cmd.Flags().MarkDeprecated("json", "use --output json instead")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json", "jsonl", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().StringSliceVar(&flags.Fields, "field", nil, "only output these fields, without table decoration (can be repeated)")
+	cmd.Flags().StringVar(&flags.Format, "format", "", "format each item using a go template")
+	cmd.MarkFlagsMutuallyExclusive("format", "field")
+	cmd.Flags().BoolVar(&flags.Count, "count", false, "only print the number of items")
+	cmd.MarkFlagsMutuallyExclusive("count", "format")
+	cmd.MarkFlagsMutuallyExclusive("count", "field")
+	cmd.Flags().BoolVar(&flags.Porcelain, "porcelain", false, "output tab separated fields without header, in a format that is stable across versions")
+	cmd.MarkFlagsMutuallyExclusive("porcelain", "output")
+	cmd.MarkFlagsMutuallyExclusive("porcelain", "json")
+	cmd.MarkFlagsMutuallyExclusive("porcelain", "field")
+	cmd.MarkFlagsMutuallyExclusive("porcelain", "format")
+	cmd.MarkFlagsMutuallyExclusive("porcelain", "count")
+}
+
+// printItems prints a slice of items according to the output flags. It
+// reports false when they should be rendered as a table by the caller instead.
+func printItems(w io.Writer, flags outputFlags, items any) (bool, error) {
+	format := flags.Output
+	if flags.Json {
+		format = "json"
+	}
+
+	switch format {
+	case "table", "json", "jsonl", "yaml":
+	default:
+		return true, fmt.Errorf("invalid output format %q, must be one of table, json, jsonl or yaml", format)
+	}
+
+	if flags.Count {
+		_, err := fmt.Fprintln(w, reflect.ValueOf(items).Len())
+		return true, err
+	}
+
+	if flags.Format != "" {
+		return true, printTemplate(w, flags.Format, items)
+	}
+
+	if flags.Porcelain {
+		return true, printPorcelain(w, items)
+	}
+
+	if len(flags.Fields) > 0 {
+		rows, err := selectFields(items, flags.Fields)
+		if err != nil {
+			return true, err
+		}
+
+		if format != "table" {
+			return true, encodeItems(w, format, rows)
+		}
+
+		return true, printFields(w, rows, flags.Fields)
+	}
+
+	if format != "table" {
+		return true, encodeItems(w, format, items)
+	}
+
+	return false, nil
+}
+
+// printItem prints a single item according to the output flags, like printItems, except that the
+// json and yaml documents hold the item itself rather than a list of one item.
+func printItem(w io.Writer, flags outputFlags, item any) (bool, error) {
+	format := flags.Output
+	if flags.Json {
+		format = "json"
+	}
+
+	if (format != "json" && format != "yaml") || flags.Count || flags.Format != "" || flags.Porcelain {
+		items := reflect.Append(reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(item)), 0, 1), reflect.ValueOf(item))
+		return printItems(w, flags, items.Interface())
+	}
+
+	if len(flags.Fields) > 0 {
+		rows, err := selectFields([]any{item}, flags.Fields)
+		if err != nil {
+			return true, err
+		}
+
+		return true, encodeItems(w, format, rows[0])
+	}
+
+	return true, encodeItems(w, format, item)
+}
+
+// printKeyValues prints the details of a single item as a table of two columns, the name of each
+// detail followed by its value.
+func printKeyValues(w io.Writer, rows [][2]string) error {
+	printer, err := newOutputTablePrinter(w)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		printer.AddField(row[0])
+		printer.AddField(row[1])
+		printer.EndRow()
+	}
+
+	return printer.Render()
+}
+
+var templateFuncs = template.FuncMap{
+	// trunc shortens a string to the given display width, e.g. {{ .Title | trunc 20 }}
+	"trunc": text.Truncate,
+}
+
+func parseTemplate(format string) (*template.Template, error) {
+	return template.New("format").Funcs(templateFuncs).Parse(format)
+}
+
+// printTemplate executes a go template against each item of a slice, one item per line.
+func printTemplate(w io.Writer, format string, items any) error {
+	tmpl, err := parseTemplate(format)
+	if err != nil {
+		return err
+	}
+
+	values := reflect.ValueOf(items)
+	for i := 0; i < values.Len(); i++ {
+		if err := tmpl.Execute(w, values.Index(i).Interface()); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeItems writes items in a machine readable format, either json, json lines or yaml. Json and
+// yaml also accept a single item.
+func encodeItems(w io.Writer, format string, items any) error {
+	switch format {
+	case "json":
+		return printJSON(w, items)
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		values := reflect.ValueOf(items)
+		for i := 0; i < values.Len(); i++ {
+			if err := encoder.Encode(values.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(items); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("cannot encode items as %s", format)
+	}
+}
+
+// selectFields converts a slice of items to a slice of maps only holding the
+// given fields. Fields are referenced by their json key.
+func selectFields(items any, fields []string) ([]map[string]any, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are decoded as json.Number, so that large integers such as ids are not printed as floats
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var rows []map[string]any
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for key, value := range row {
+			row[key] = parseNumbers(value)
+		}
+	}
+
+	projected := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		item := make(map[string]any)
+		for _, field := range fields {
+			value, ok := row[field]
+			if !ok {
+				var available []string
+				for key := range row {
+					available = append(available, key)
+				}
+				sort.Strings(available)
+				return nil, fmt.Errorf("unknown field %q, available fields: %s", field, strings.Join(available, ", "))
+			}
+
+			item[field] = value
+		}
+		projected = append(projected, item)
+	}
+
+	return projected, nil
+}
+
+// parseNumbers replaces the json.Number values decoded by selectFields by an int64, or a float64
+// when they are not integers, so that yaml encodes them as numbers rather than strings.
+func parseNumbers(value any) any {
+	switch value := value.(type) {
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value
+	case map[string]any:
+		for key, v := range value {
+			value[key] = parseNumbers(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = parseNumbers(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// printPorcelain prints the porcelain row of each item on its own line, separated by tabs. Tabs
+// and newlines within values are replaced by spaces, so that each line is a single row.
+func printPorcelain(w io.Writer, items any) error {
+	replacer := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+	values := reflect.ValueOf(items)
+	for i := 0; i < values.Len(); i++ {
+		item, ok := values.Index(i).Interface().(porcelainItem)
+		if !ok {
+			return errors.New("porcelain output is not supported by this command")
+		}
+
+		row := item.porcelainRow()
+		for j, value := range row {
+			row[j] = replacer.Replace(value)
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printFields prints the given fields of each row on its own line, separated by tabs.
+func printFields(w io.Writer, rows []map[string]any, fields []string) error {
+	for _, row := range rows {
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = fmt.Sprint(row[field])
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
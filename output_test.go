@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+var testTabs = []Tab{
+	{WindowID: 1, Index: 1, Title: "Example", URL: "https://example.com/?a=1&b=2", ID: "A1", Location: "unpinned"},
+	{WindowID: 1, Index: 2, Title: "Go\tDev", URL: "https://go.dev", ID: "B2", Location: "pinned", Pinned: true},
+}
+
+func TestTablePrinter(t *testing.T) {
+	tests := []struct {
+		name  string
+		isTTY bool
+		want  string
+	}{
+		{
+			name:  "tty",
+			isTTY: true,
+			want:  "Index  Title\n1      Example\n2      Go Dev\n",
+		},
+		{
+			name:  "not a tty",
+			isTTY: false,
+			want:  "1\tExample\n2\tGo Dev\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printer := newTablePrinter(&buf, tt.isTTY, 80)
+			printer.AddHeader([]string{"Index", "Title"})
+			printer.AddField("1")
+			printer.AddField("Example")
+			printer.EndRow()
+			printer.AddField("2")
+			printer.AddField("Go Dev")
+			printer.EndRow()
+			if err := printer.Render(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   outputFlags
+		compact bool
+		want    string
+	}{
+		{
+			name:  "json",
+			flags: outputFlags{Output: "json"},
+			want: `[
+  {
+    "windowId": 1,
+    "index": 1,
+    "title": "Example",
+    "url": "https://example.com/?a=1&b=2",
+    "id": "A1",
+    "location": "unpinned",
+    "pinned": false
+  },
+  {
+    "windowId": 1,
+    "index": 2,
+    "title": "Go\tDev",
+    "url": "https://go.dev",
+    "id": "B2",
+    "location": "pinned",
+    "pinned": true
+  }
+]
+`,
+		},
+		{
+			name:    "compact json",
+			flags:   outputFlags{Output: "json", Fields: []string{"index"}},
+			compact: true,
+			want:    "[{\"index\":1},{\"index\":2}]\n",
+		},
+		{
+			name:  "jsonl",
+			flags: outputFlags{Output: "jsonl", Fields: []string{"index", "url"}},
+			want:  "{\"index\":1,\"url\":\"https://example.com/?a=1&b=2\"}\n{\"index\":2,\"url\":\"https://go.dev\"}\n",
+		},
+		{
+			name:  "porcelain",
+			flags: outputFlags{Output: "table", Porcelain: true},
+			want:  "1\t1\tA1\tunpinned\tfalse\tExample\thttps://example.com/?a=1&b=2\n1\t2\tB2\tpinned\ttrue\tGo Dev\thttps://go.dev\n",
+		},
+		{
+			name:  "fields",
+			flags: outputFlags{Output: "table", Fields: []string{"id", "index"}},
+			want:  "A1\t1\nB2\t2\n",
+		},
+		{
+			name:  "count",
+			flags: outputFlags{Output: "table", Count: true},
+			want:  "2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compact = tt.compact
+			defer func() { compact = false }()
+
+			var buf bytes.Buffer
+			done, err := printItems(&buf, tt.flags, testTabs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !done {
+				t.Fatal("items were left to the table printer")
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintItemsTable(t *testing.T) {
+	var buf bytes.Buffer
+	done, err := printItems(&buf, outputFlags{Output: "table"}, testTabs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Error("table output should be left to the caller")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestPrintItemsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags outputFlags
+		items any
+	}{
+		{name: "invalid format", flags: outputFlags{Output: "xml"}, items: testTabs},
+		{name: "unknown field", flags: outputFlags{Output: "table", Fields: []string{"nope"}}, items: testTabs},
+		{name: "porcelain unsupported", flags: outputFlags{Output: "table", Porcelain: true}, items: []struct{ Name string }{{Name: "x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := printItems(&buf, tt.flags, tt.items); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestPrintItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags outputFlags
+		want  string
+	}{
+		{
+			name:  "json",
+			flags: outputFlags{Output: "json", Fields: []string{"id", "index"}},
+			want:  "{\n  \"id\": \"A1\",\n  \"index\": 1\n}\n",
+		},
+		{
+			name:  "yaml",
+			flags: outputFlags{Output: "yaml", Fields: []string{"id"}},
+			want:  "id: A1\n",
+		},
+		{
+			name:  "porcelain",
+			flags: outputFlags{Output: "table", Porcelain: true},
+			want:  "1\t1\tA1\tunpinned\tfalse\tExample\thttps://example.com/?a=1&b=2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := printItem(&buf, tt.flags, testTabs[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectFieldsKeepsIntegers(t *testing.T) {
+	items := []struct {
+		ID int64 `json:"id"`
+	}{{ID: 13376582907543210}}
+
+	tests := []struct {
+		name  string
+		flags outputFlags
+		want  string
+	}{
+		{name: "table", flags: outputFlags{Output: "table", Fields: []string{"id"}}, want: "13376582907543210\n"},
+		{name: "jsonl", flags: outputFlags{Output: "jsonl", Fields: []string{"id"}}, want: "{\"id\":13376582907543210}\n"},
+		{name: "yaml", flags: outputFlags{Output: "yaml", Fields: []string{"id"}}, want: "- id: 13376582907543210\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := printItems(&buf, tt.flags, items); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "field with output", args: []string{"--field", "id", "-o", "jsonl"}, want: "{\"id\":\"A1\"}\n{\"id\":\"B2\"}\n"},
+		{name: "field with json", args: []string{"--field", "id", "--json", "--compact"}, want: "[{\"id\":\"A1\"},{\"id\":\"B2\"}]\n"},
+		{name: "count with output", args: []string{"--count", "-o", "json"}, want: "2\n"},
+		{name: "porcelain with output", args: []string{"--porcelain", "-o", "json"}, wantErr: true},
+		{name: "porcelain with field", args: []string{"--porcelain", "--field", "id"}, wantErr: true},
+		{name: "count with field", args: []string{"--count", "--field", "id"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { compact = false }()
+
+			var flags outputFlags
+			cmd := &cobra.Command{
+				Use: "list",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					_, err := printItems(cmd.OutOrStdout(), flags, testTabs)
+					return err
+				},
+			}
+			cmd.PersistentFlags().BoolVar(&compact, "compact", false, "")
+			addOutputFlags(cmd, &flags)
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// cobra writes the deprecation warning of --json to the output set on the command
+			if got := stdout.String(); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
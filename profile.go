@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// userDataPath is the directory holding the data of every profile of Arc.
+var userDataPath = filepath.Join(os.Getenv("HOME"), "Library", "Application Support", "Arc", "User Data")
+
+var localStatePath = filepath.Join(userDataPath, "Local State")
+
+func NewCmdProfile() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles",
+	}
+
+	cmd.AddCommand(NewCmdProfileList())
+	return cmd
+}
+
+type Profile struct {
+	Directory string `json:"directory" yaml:"directory"`
+	Name      string `json:"name" yaml:"name"`
+}
+
+func (p Profile) porcelainRow() []string {
+	return []string{p.Directory, p.Name}
+}
+
+// findProfile looks up a profile by display name, case-insensitively, or by directory.
+func findProfile(name string) (Profile, error) {
+	profiles, err := listProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var names []string
+	for _, profile := range profiles {
+		if profile.Directory == name || strings.EqualFold(profile.Name, name) {
+			return profile, nil
+		}
+		names = append(names, profile.Name)
+	}
+
+	return Profile{}, notFoundf("no profile named %q, available profiles: %s", name, strings.Join(names, ", "))
+}
+
+// listProfiles reads the profiles from the Local State file of Arc, which does
+// not require Arc to be running.
+func listProfiles() ([]Profile, error) {
+	content, err := os.ReadFile(localStatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the profiles: %w", err)
+	}
+
+	var localState struct {
+		Profile struct {
+			InfoCache map[string]struct {
+				Name string `json:"name"`
+			} `json:"info_cache"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(content, &localState); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", localStatePath, err)
+	}
+
+	var profiles []Profile
+	for directory, info := range localState.Profile.InfoCache {
+		profiles = append(profiles, Profile{Directory: directory, Name: info.Name})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Directory < profiles[j].Directory
+	})
+
+	return profiles, nil
+}
+
+func NewCmdProfileList() *cobra.Command {
+	var flags struct {
+		outputFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := listProfiles()
+			if err != nil {
+				return err
+			}
+
+			if ok, err := printItems(cmd.OutOrStdout(), flags.outputFlags, profiles); ok || err != nil {
+				return err
+			}
+
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			printer.AddHeader([]string{"Directory", "Name"})
+			for _, profile := range profiles {
+				printer.AddField(profile.Directory)
+				printer.AddField(profile.Name)
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	addOutputFlags(cmd, &flags.outputFlags)
+	return cmd
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/cli/go-gh/v2/pkg/prompter"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// canPrompt reports whether both stdin and stdout are terminals, so that the user can be prompted.
+func canPrompt() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// pickItems lets the user select some of the options in a list that is filtered while typing,
+// and returns the indexes of the selected options.
+func pickItems(message string, options []string) ([]int, error) {
+	core.DisableColor = !colorEnabled()
+	return prompter.New(os.Stdin, os.Stdout, os.Stderr).MultiSelect(message, nil, options)
+}
+
+// confirm asks a yes or no question, answered with no by default.
+func confirm(cmd *cobra.Command, message string) (bool, error) {
+	cmd.Printf("%s [y/N] ", message)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdScreenshot() *cobra.Command {
+	var flags struct {
+		Window int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "screenshot [file]",
+		Short: "Capture a window as a png image",
+		Long: `Capture a window as a png image and print the path of the saved file.
+
+The window is brought to the front and captured with screencapture, which requires the
+screen recording permission for the terminal. The file defaults to a timestamped name in the
+current directory.
+
+Only the visible part of the page is captured: Arc does not expose the page size nor a way to
+render off-screen content, so full page captures are not supported.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := time.Now().Format("arc-screenshot-20060102-150405.png")
+			if len(args) > 0 {
+				file = args[0]
+			}
+
+			raise := ""
+			if flags.Window != 0 {
+				raise = fmt.Sprintf("set index of window %d to 1", flags.Window)
+			}
+
+			output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				%s
+				activate
+				delay 0.5
+				set {x1, y1, x2, y2} to bounds of front window
+				return (x1 as text) & "," & y1 & "," & (x2 - x1) & "," & (y2 - y1)
+			end tell`, raise))
+			if err != nil {
+				return err
+			}
+
+			bounds := strings.TrimSpace(string(output))
+			if out, err := exec.Command("screencapture", "-x", "-t", "png", "-R", bounds, file).CombinedOutput(); err != nil {
+				return fmt.Errorf("screencapture: %w: %s", err, strings.TrimSpace(string(out)))
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), file)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window to capture (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	return cmd
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdSession() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Save and restore windows and tabs",
+	}
+
+	cmd.AddCommand(NewCmdSessionSave())
+	cmd.AddCommand(NewCmdSessionRestore())
+	return cmd
+}
+
+// listSession returns every window, along with its tabs.
+func listSession() ([]Window, error) {
+	windows, err := listWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	tabs, err := listTabs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tab := range tabs {
+		for i := range windows {
+			if windows[i].ID == tab.WindowID {
+				windows[i].Tabs = append(windows[i].Tabs, tab)
+			}
+		}
+	}
+
+	return windows, nil
+}
+
+func NewCmdSessionSave() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save [file]",
+		Short: "Save every window and tab as json",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windows, err := listSession()
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if len(args) > 0 {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			return printJSON(w, windows)
+		},
+	}
+
+	return cmd
+}
+
+// restoreStep describes how to reopen the tabs of a saved window.
+type restoreStep struct {
+	Window Window
+	// Target is the id of the current window the tabs are opened in, or 0 to create a new window.
+	Target int
+	URLs   []string
+}
+
+func NewCmdSessionRestore() *cobra.Command {
+	var flags struct {
+		Merge  bool
+		DryRun bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Reopen the windows and tabs saved by session save",
+		Long: `Reopen the windows and tabs saved by session save.
+
+Each saved window is recreated with its tabs, in order. With --merge, the tabs of a saved window are
+opened in the current window with the same id instead, as long as it has the same incognito mode.
+Saved windows without any tab are skipped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			var saved []Window
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return fmt.Errorf("invalid session file %s: %w", args[0], err)
+			}
+
+			var current []Window
+			if flags.Merge {
+				if current, err = listWindows(); err != nil {
+					return err
+				}
+			}
+
+			// Merged windows go first, since creating a window shifts the ids of the existing ones.
+			var merged, created []restoreStep
+			for _, window := range saved {
+				step := restoreStep{Window: window}
+				for _, tab := range window.Tabs {
+					if tab.URL != "" {
+						step.URLs = append(step.URLs, tab.URL)
+					}
+				}
+
+				if len(step.URLs) == 0 {
+					infof(cmd, "skipping window %d %q: no tab to restore\n", window.ID, window.Title)
+					continue
+				}
+
+				if flags.Merge && window.ID >= 1 && window.ID <= len(current) && current[window.ID-1].Incognito == window.Incognito {
+					step.Target = window.ID
+					merged = append(merged, step)
+				} else {
+					created = append(created, step)
+				}
+			}
+
+			for _, step := range append(merged, created...) {
+				if flags.DryRun {
+					destination := "new window"
+					if step.Target != 0 {
+						destination = fmt.Sprintf("window %d", step.Target)
+					}
+					if step.Window.Incognito {
+						destination += " (incognito)"
+					}
+
+					fmt.Fprintln(cmd.OutOrStdout(), destination)
+					for _, url := range step.URLs {
+						fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", url)
+					}
+					continue
+				}
+
+				makeWindow := "make new window"
+				if step.Window.Incognito {
+					makeWindow = "make new window with properties {incognito:true}"
+				}
+				if step.Target != 0 {
+					makeWindow = ""
+				}
+
+				var makeTabs []string
+				for _, url := range step.URLs {
+					makeTabs = append(makeTabs, fmt.Sprintf(`make new tab with properties {URL:"%s"}`, escapeApplescript(url)))
+				}
+
+				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+					%s
+					tell %s
+						%s
+					end tell
+				end tell`, makeWindow, windowSpecifier(step.Target), strings.Join(makeTabs, "\n"))); err != nil {
+					return err
+				}
+			}
+
+			if flags.DryRun {
+				return nil
+			}
+
+			if err := activateArc(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.Merge, "merge", false, "open the tabs in the current windows instead of new ones")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "only print what would be opened")
+	return cmd
+}
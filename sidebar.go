@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	_ "embed"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed applescript/sidebar.applescript
+var sidebarScript string
+
+// setSidebar shows, hides or toggles the sidebar of the front window, and returns its state
+// afterwards, which is unknown when it cannot be read from the View menu.
+func setSidebar(action string) (string, error) {
+	output, err := runApplescript(sidebarScript, action)
+	if err != nil {
+		return "", uiScriptingError(err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+func NewCmdSidebar() *cobra.Command {
+	var flags struct {
+		Show   bool
+		Hide   bool
+		Toggle bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "sidebar",
+		Short: "Show or hide the sidebar of the front window",
+		Long: `Show or hide the sidebar of the front window, or toggle it, and print whether it is shown afterwards.
+
+The sidebar is shown or hidden with Cmd+S, sent through System Events. This uses ui scripting
+(accessibility permission, see arc doctor). Whether the sidebar is shown is read from the View menu
+first, so that --show and --hide do nothing when it is already in that state. When it cannot be read,
+--show and --hide fail, while --toggle sends the shortcut regardless and prints unknown.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action := "toggle"
+			if flags.Show {
+				action = "show"
+			} else if flags.Hide {
+				action = "hide"
+			}
+
+			state, err := setSidebar(action)
+			if err != nil {
+				return err
+			}
+
+			if state == "unknown" && action != "toggle" {
+				return fmt.Errorf("could not read whether the sidebar is shown, use --toggle instead")
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), state)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.Show, "show", false, "show the sidebar")
+	cmd.Flags().BoolVar(&flags.Hide, "hide", false, "hide the sidebar")
+	cmd.Flags().BoolVar(&flags.Toggle, "toggle", false, "show the sidebar if it is hidden, and hide it otherwise (the default)")
+	cmd.MarkFlagsMutuallyExclusive("show", "hide", "toggle")
+	return cmd
+}
@@ -3,15 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
 
 	_ "embed"
 
-	"github.com/cli/go-gh/v2/pkg/tableprinter"
-	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 func NewCmdSpace() *cobra.Command {
@@ -21,7 +18,10 @@ func NewCmdSpace() *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdSpaceFocus())
+	cmd.AddCommand(NewCmdSpaceSwitch())
 	cmd.AddCommand(NewCmdSpaceList())
+	cmd.AddCommand(NewCmdSpaceCreate())
+	cmd.AddCommand(NewCmdSpaceRename())
 	return cmd
 }
 
@@ -50,54 +50,356 @@ func NewCmdSpaceFocus() *cobra.Command {
 	return cmd
 }
 
+func NewCmdSpaceSwitch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "switch <name-or-index>",
+		Short: "Switch the front window to a space",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			space, err := findSpace(args[0])
+			if err != nil {
+				return err
+			}
+
+			return switchSpace(space)
+		},
+	}
+
+	return cmd
+}
+
+// switchSpace switches the front window to a space.
+func switchSpace(space Space) error {
+	_, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+		tell front window
+			tell space %d to focus
+		end tell
+	end tell`, space.ID))
+	return err
+}
+
+// findSpace looks up a space of the front window, either by its 1-based index
+// or by a case-insensitive substring of its title.
+func findSpace(query string) (Space, error) {
+	frontSpaces, err := listFrontSpaces()
+	if err != nil {
+		return Space{}, err
+	}
+
+	var titles []string
+	for _, space := range frontSpaces {
+		titles = append(titles, space.Title)
+	}
+
+	if index, err := strconv.Atoi(query); err == nil {
+		for _, space := range frontSpaces {
+			if space.ID == index {
+				return space, nil
+			}
+		}
+	}
+
+	for _, space := range frontSpaces {
+		if strings.Contains(strings.ToLower(space.Title), strings.ToLower(query)) {
+			return space, nil
+		}
+	}
+
+	return Space{}, notFoundf("no space found matching %q, available spaces: %s", query, strings.Join(titles, ", "))
+}
+
+//go:embed applescript/create-space.applescript
+var createSpaceScript string
+
+func NewCmdSpaceCreate() *cobra.Command {
+	var flags struct {
+		AllowDuplicate bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a space in the front window",
+		Long: `Create a space in the front window.
+
+Arc does not allow creating spaces through applescript, so the New Space form is opened from the Spaces
+menu and filled through System Events. This uses ui scripting (accessibility permission, see arc
+doctor).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if !flags.AllowDuplicate {
+				spaces, err := listSpaces()
+				if err != nil {
+					return err
+				}
+
+				for _, space := range spaces {
+					if space.WindowID == 1 && strings.EqualFold(space.Title, name) {
+						return fmt.Errorf("space %q already exists, use --allow-duplicate to create it anyway", space.Title)
+					}
+				}
+			}
+
+			if _, err := runApplescript(createSpaceScript, name); err != nil {
+				return uiScriptingError(err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.AllowDuplicate, "allow-duplicate", false, "create the space even if one with the same name exists")
+	return cmd
+}
+
+//go:embed applescript/rename-space.applescript
+var renameSpaceScript string
+
+func NewCmdSpaceRename() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <name-or-index> <new-name>",
+		Short: "Rename a space of the front window",
+		Long: `Rename a space of the front window.
+
+The space is looked up by its 1-based index, or by its title: an exact match wins, otherwise the title
+must contain the given name and only one space may match.
+
+Arc does not allow renaming spaces through applescript, so the space is focused and renamed from the
+Spaces menu through System Events. This uses ui scripting (accessibility permission, see arc doctor).`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			space, err := findSingleSpace(args[0])
+			if err != nil {
+				return err
+			}
+
+			if _, err := runApplescript(renameSpaceScript, strconv.Itoa(space.ID), args[1]); err != nil {
+				return uiScriptingError(err)
+			}
+
+			spaces, err := listFrontSpaces()
+			if err != nil {
+				return err
+			}
+
+			for _, renamed := range spaces {
+				if renamed.ID == space.ID && renamed.Title != args[1] {
+					return fmt.Errorf("space %d was not renamed: its title is %q instead of %q", space.ID, renamed.Title, args[1])
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// findSingleSpace looks up a space of the front window like findSpace, but fails
+// when the query matches the titles of several spaces.
+func findSingleSpace(query string) (Space, error) {
+	frontSpaces, err := listFrontSpaces()
+	if err != nil {
+		return Space{}, err
+	}
+
+	if index, err := strconv.Atoi(query); err == nil {
+		for _, space := range frontSpaces {
+			if space.ID == index {
+				return space, nil
+			}
+		}
+	}
+
+	var matches []Space
+	for _, space := range frontSpaces {
+		if space.Title == query {
+			return space, nil
+		}
+
+		if strings.Contains(strings.ToLower(space.Title), strings.ToLower(query)) {
+			matches = append(matches, space)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		var titles []string
+		for _, space := range frontSpaces {
+			titles = append(titles, space.Title)
+		}
+		return Space{}, notFoundf("no space found matching %q, available spaces: %s", query, strings.Join(titles, ", "))
+	case 1:
+		return matches[0], nil
+	}
+
+	var candidates []string
+	for _, space := range matches {
+		candidates = append(candidates, fmt.Sprintf("%d %q", space.ID, space.Title))
+	}
+	return Space{}, fmt.Errorf("several spaces match %q, use the index or the full title of one of: %s", query, strings.Join(candidates, ", "))
+}
+
+// listFrontSpaces returns the spaces of the front window.
+func listFrontSpaces() ([]Space, error) {
+	spaces, err := listSpaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var frontSpaces []Space
+	for _, space := range spaces {
+		if space.WindowID == 1 {
+			frontSpaces = append(frontSpaces, space)
+		}
+	}
+
+	return frontSpaces, nil
+}
+
 //go:embed applescript/list-spaces.applescript
 var listSpacesScript string
 
 type Space struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
+	ID       int    `json:"id" yaml:"id"`
+	Title    string `json:"title" yaml:"title"`
+	WindowID int    `json:"windowId" yaml:"windowId"`
+}
+
+func (s Space) porcelainRow() []string {
+	return []string{strconv.Itoa(s.WindowID), strconv.Itoa(s.ID), s.Title}
+}
+
+func listSpaces() ([]Space, error) {
+	output, err := runApplescript(listSpacesScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var spaces []Space
+	if err := json.Unmarshal(output, &spaces); err != nil {
+		return nil, err
+	}
+
+	return spaces, nil
+}
+
+// SpaceTabs is a space along with its tabs, as listed by `space list --tabs`.
+type SpaceTabs struct {
+	Space `yaml:",inline"`
+	Tabs  []Tab `json:"tabs" yaml:"tabs"`
+}
+
+//go:embed applescript/list-space-tabs.applescript
+var listSpaceTabsScript string
+
+// listSpaceTabs returns the spaces with their tabs, in sidebar order.
+func listSpaceTabs() ([]SpaceTabs, error) {
+	spaces, err := listSpaces()
+	if err != nil {
+		return nil, err
+	}
+
+	tabs, err := listTabs()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := runApplescript(listSpaceTabsScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var spaceTabIDs []struct {
+		WindowID int      `json:"windowId"`
+		ID       int      `json:"id"`
+		TabIDs   []string `json:"tabIds"`
+	}
+	if err := json.Unmarshal(output, &spaceTabIDs); err != nil {
+		return nil, err
+	}
+
+	type tabKey struct {
+		WindowID int
+		ID       string
+	}
+	tabsByID := make(map[tabKey]Tab)
+	for _, tab := range tabs {
+		tabsByID[tabKey{tab.WindowID, tab.ID}] = tab
+	}
+
+	type spaceKey struct {
+		WindowID int
+		ID       int
+	}
+	tabIDs := make(map[spaceKey][]string)
+	for _, space := range spaceTabIDs {
+		tabIDs[spaceKey{space.WindowID, space.ID}] = space.TabIDs
+	}
+
+	var items []SpaceTabs
+	for _, space := range spaces {
+		item := SpaceTabs{Space: space, Tabs: []Tab{}}
+		for _, id := range tabIDs[spaceKey{space.WindowID, space.ID}] {
+			if tab, ok := tabsByID[tabKey{space.WindowID, id}]; ok {
+				item.Tabs = append(item.Tabs, tab)
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
 }
 
 func NewCmdSpaceList() *cobra.Command {
 	var flags struct {
-		Json bool
+		Window int
+		Tabs   bool
+		outputFlags
 	}
 
 	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List spaces",
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List spaces of every window",
+		Long: `List spaces of every window.
+
+With --tabs, the tabs of each space are listed under it. Arc does not tell the space of a tab, so
+the tabs are matched to the spaces listing them in Arc's scripting dictionary, by id. Favorites
+belong to every space and are left out.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output, err := runApplescript(listSpacesScript)
-			if err != nil {
-				return err
+			if flags.Tabs {
+				return listSpacesWithTabs(cmd, flags.Window, flags.outputFlags)
 			}
 
-			var spaces []Space
-			if err := json.Unmarshal(output, &spaces); err != nil {
+			spaces, err := listSpaces()
+			if err != nil {
 				return err
 			}
 
-			if flags.Json {
-				encoder := json.NewEncoder(os.Stdout)
-				encoder.SetIndent("", "  ")
-				encoder.SetEscapeHTML(false)
-				return encoder.Encode(spaces)
+			if cmd.Flags().Changed("window") {
+				var windowSpaces []Space
+				for _, space := range spaces {
+					if space.WindowID == flags.Window {
+						windowSpaces = append(windowSpaces, space)
+					}
+				}
+				spaces = windowSpaces
 			}
 
-			var printer tableprinter.TablePrinter
-			if !isatty.IsTerminal(os.Stdout.Fd()) {
-				printer = tableprinter.New(os.Stdout, false, 0)
-			} else {
-				w, _, err := term.GetSize(int(os.Stdout.Fd()))
-				if err != nil {
-					return err
-				}
+			if ok, err := printItems(cmd.OutOrStdout(), flags.outputFlags, spaces); ok || err != nil {
+				return err
+			}
 
-				printer = tableprinter.New(os.Stdout, true, w)
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
 			}
 
-			printer.AddHeader([]string{"ID", "Title"})
+			printer.AddHeader([]string{"Window", "ID", "Title"})
 			for _, space := range spaces {
+				printer.AddField(strconv.Itoa(space.WindowID))
 				printer.AddField(strconv.Itoa(space.ID))
 				printer.AddField(space.Title)
 				printer.EndRow()
@@ -107,6 +409,56 @@ func NewCmdSpaceList() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().BoolVar(&flags.Json, "json", false, "output as json")
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "only show spaces of this window")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().BoolVar(&flags.Tabs, "tabs", false, "list the tabs of each space under it")
+	addOutputFlags(cmd, &flags.outputFlags)
+	// the rows of a space and of its tabs have different columns, porcelain output has no shape for both
+	cmd.MarkFlagsMutuallyExclusive("tabs", "porcelain")
 	return cmd
 }
+
+func listSpacesWithTabs(cmd *cobra.Command, window int, output outputFlags) error {
+	spaces, err := listSpaceTabs()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("window") {
+		var windowSpaces []SpaceTabs
+		for _, space := range spaces {
+			if space.WindowID == window {
+				windowSpaces = append(windowSpaces, space)
+			}
+		}
+		spaces = windowSpaces
+	}
+
+	if ok, err := printItems(cmd.OutOrStdout(), output, spaces); ok || err != nil {
+		return err
+	}
+
+	printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+
+	printer.AddHeader([]string{"Window", "ID", "Title", "URL"})
+	for _, space := range spaces {
+		printer.AddField(strconv.Itoa(space.WindowID))
+		printer.AddField(strconv.Itoa(space.ID))
+		printer.AddField(space.Title)
+		printer.AddField("")
+		printer.EndRow()
+
+		for _, tab := range space.Tabs {
+			printer.AddField("")
+			printer.AddField("  " + strconv.Itoa(tab.Index))
+			printer.AddField("  " + tab.Title)
+			printer.AddField(tab.URL)
+			printer.EndRow()
+		}
+	}
+
+	return printer.Render()
+}
@@ -1,27 +1,41 @@
 package main
 
 import (
+	"bytes"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
+	neturl "net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	_ "embed"
 
-	"github.com/cli/go-gh/v2/pkg/tableprinter"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 type Tab struct {
-	Title    string `json:"title"`
-	URL      string `json:"url"`
-	ID       string `json:"id"`
-	Location string `json:"location"`
+	WindowID int    `json:"windowId" yaml:"windowId"`
+	Index    int    `json:"index" yaml:"index"`
+	Title    string `json:"title" yaml:"title"`
+	URL      string `json:"url" yaml:"url"`
+	ID       string `json:"id" yaml:"id"`
+	Location string `json:"location" yaml:"location"`
+	// Pinned reports whether the tab is in the pinned section of its space, favorites are not pinned.
+	Pinned bool `json:"pinned" yaml:"pinned"`
+}
+
+func (t Tab) porcelainRow() []string {
+	return []string{strconv.Itoa(t.WindowID), strconv.Itoa(t.Index), t.ID, t.Location, strconv.FormatBool(t.Pinned), t.Title, t.URL}
 }
 
 type State string
@@ -53,12 +67,41 @@ func NewCmdTab() *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdTabGet())
+	cmd.AddCommand(NewCmdTabURL())
+	cmd.AddCommand(NewCmdTabTitle())
 	cmd.AddCommand(NewCmdTabList())
+	cmd.AddCommand(NewCmdTabInfo())
 	cmd.AddCommand(NewCmdTabFocus())
 	cmd.AddCommand(NewCmdTabCreate())
 	cmd.AddCommand(NewCmdTabClose())
 	cmd.AddCommand(NewCmdTabReload())
+	cmd.AddCommand(NewCmdTabRestore())
 	cmd.AddCommand(NewCmdTabExecute())
+	cmd.AddCommand(NewCmdTabMove())
+	cmd.AddCommand(NewCmdTabDuplicate())
+	cmd.AddCommand(NewCmdTabPin())
+	cmd.AddCommand(NewCmdTabUnpin())
+	cmd.AddCommand(NewCmdTabBookmark())
+	cmd.AddCommand(NewCmdTabArchive())
+	cmd.AddCommand(NewCmdTabSetTitle())
+	cmd.AddCommand(NewCmdTabPrint())
+	cmd.AddCommand(NewCmdTabSearch())
+	cmd.AddCommand(NewCmdTabExport())
+	cmd.AddCommand(NewCmdTabImport())
+	cmd.AddCommand(NewCmdTabOpenTemplate())
+	cmd.AddCommand(NewCmdTabCopyURL())
+	cmd.AddCommand(NewCmdTabMoveToSpace())
+	cmd.AddCommand(NewCmdTabActivateAudio())
+	cmd.AddCommand(NewCmdTabScroll())
+	cmd.AddCommand(NewCmdTabFind())
+	cmd.AddCommand(NewCmdTabMute())
+	cmd.AddCommand(NewCmdTabUnmute())
+	cmd.AddCommand(NewCmdTabGoto())
+	cmd.AddCommand(NewCmdTabBack())
+	cmd.AddCommand(NewCmdTabForward())
+	cmd.AddCommand(NewCmdTabWait())
+	cmd.AddCommand(NewCmdTabWaitForURL())
+	cmd.AddCommand(NewCmdTabCaptureURLOnRedirect())
 
 	return cmd
 }
@@ -69,377 +112,2897 @@ func NewCmdTabGet() *cobra.Command {
 		Short: "Get information about the active tab",
 	}
 
-	cmd.AddCommand(NewCmdTabUrl())
+	cmd.AddCommand(NewCmdTabURL())
 	cmd.AddCommand(NewCmdTabTitle())
 
 	return cmd
 }
 
-func NewCmdTabUrl() *cobra.Command {
+func NewCmdTabURL() *cobra.Command {
+	var flags struct {
+		Window int
+		Copy   bool
+		Json   bool
+	}
+
 	cmd := &cobra.Command{
 		Use:   "url",
 		Short: "Get the url of the active tab",
+		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output, err := runApplescript(`tell application "Arc" to set currentURL to URL of active tab of front window`)
+			tab, err := getActiveTab(flags.Window)
 			if err != nil {
 				return err
 			}
 
-			if _, err := os.Stdout.Write(output); err != nil {
-				return err
+			if flags.Copy {
+				return copyToClipboard(tab.URL)
+			}
+
+			if flags.Json {
+				return printJSON(cmd.OutOrStdout(), map[string]string{"url": tab.URL, "title": tab.Title})
 			}
 
+			fmt.Fprintln(cmd.OutOrStdout(), tab.URL)
 			return nil
 		},
 	}
 
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().BoolVar(&flags.Copy, "copy", false, "copy the url to the clipboard instead of printing it")
+	cmd.Flags().BoolVar(&flags.Json, "json", false, "output the url and title as json")
 	return cmd
 }
 
+// getActiveTab returns the active tab of a window, or of the front window when window is 0.
+func getActiveTab(window int) (Tab, error) {
+	tab, _, err := getActiveTabAndSpace(window)
+	return tab, err
+}
+
+// getActiveTabAndSpace returns the active tab of a window, like getActiveTab, and the title of the
+// active space, which is empty when Arc does not report it, e.g. for little arc windows.
+func getActiveTabAndSpace(window int) (Tab, string, error) {
+	output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+		tell %s
+			set _space to ""
+			try
+				set _space to title of active space
+			end try
+			set _index to 1
+			set _id to id of active tab
+			repeat with _tab in every tab
+				if id of _tab is _id then exit repeat
+				set _index to _index + 1
+			end repeat
+			tell active tab
+				return (_index as text) & linefeed & _id & linefeed & URL & linefeed & _space & linefeed & title
+			end tell
+		end tell
+	end tell`, windowSpecifier(window)))
+	if err != nil {
+		return Tab{}, "", err
+	}
+
+	fields := strings.SplitN(strings.TrimSuffix(string(output), "\n"), "\n", 5)
+	if len(fields) != 5 {
+		return Tab{}, "", fmt.Errorf("unexpected output: %s", output)
+	}
+
+	index, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Tab{}, "", err
+	}
+
+	windowID := window
+	if windowID == 0 {
+		windowID = 1
+	}
+
+	return Tab{
+		WindowID: windowID,
+		Index:    index,
+		ID:       fields[1],
+		URL:      fields[2],
+		Title:    fields[4],
+	}, fields[3], nil
+}
+
 func NewCmdTabTitle() *cobra.Command {
+	var flags struct {
+		Window int
+		Format string
+	}
+
 	cmd := &cobra.Command{
 		Use:   "title",
 		Short: "Get the title of the active tab",
+		Example: `  # print a markdown link to the active tab
+  arc tab title --format '[{{.Title}}]({{.URL}})'`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output, err := runApplescript(`tell application "Arc" to set currentTitle to title of active tab of window 1`)
+			tab, err := getActiveTab(flags.Window)
+			if err != nil {
+				return err
+			}
+
+			if !cmd.Flags().Changed("format") {
+				fmt.Fprintln(cmd.OutOrStdout(), tab.Title)
+				return nil
+			}
+
+			tmpl, err := parseTemplate(flags.Format)
 			if err != nil {
 				return err
 			}
 
-			if _, err := os.Stdout.Write(output); err != nil {
+			if err := tmpl.Execute(cmd.OutOrStdout(), tab); err != nil {
 				return err
 			}
 
+			fmt.Fprintln(cmd.OutOrStdout())
 			return nil
 		},
 	}
 
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().StringVar(&flags.Format, "format", "", "format the tab using a go template")
 	return cmd
 }
 
 func NewCmdTabCreate() *cobra.Command {
 	var flags struct {
-		Space     int
-		LittleArc bool
+		Window     int
+		Space      int
+		LittleArc  bool
+		Background bool
+		Delay      time.Duration
+		Clipboard  bool
+		reuseFlags
+		waitFlags
 	}
 	cmd := &cobra.Command{
-		Use:     "create <url>",
-		Short:   `Create a new tab.`,
+		Use:   "create [url]",
+		Short: `Create a new tab.`,
+		Long: `Create a new tab.
+
+When no url is given and stdin is not a terminal, urls are read from stdin, one per line, and each one is
+opened in its own tab. Blank lines and lines starting with # are skipped. With --clipboard, they are read
+from the clipboard instead.
+
+With --reuse, a url already open in a tab of any window selects that tab instead of opening a new one.`,
 		Aliases: []string{"open", "new"},
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var osascript string
-			if flags.LittleArc {
-				osascript = fmt.Sprintf(`tell application "Arc" to make new tab with properties {URL:"%s"}`, args[0])
-			} else if cmd.Flags().Changed("space") {
-				osascript = fmt.Sprintf(`tell application "Arc"
-				    tell space %d
-					    make new tab with properties {URL:"%s"}
-					end tell
-					activate
-			    end tell`, flags.Space, args[0])
-			} else {
-				osascript = fmt.Sprintf(`tell application "Arc"
-					tell front window
-					  make new tab with properties {URL:"%s"}
-					end tell
-					activate
-				end tell`, args[0])
+			urls, err := urlsFromArgsOrClipboard(cmd, args, flags.Clipboard)
+			if err != nil {
+				return err
 			}
 
-			if _, err := runApplescript(osascript); err != nil {
-				return err
+			if len(urls) == 0 {
+				// create a single blank tab
+				urls = []string{""}
 			}
 
-			return nil
+			reused := false
+			for i, url := range urls {
+				if i > 0 {
+					time.Sleep(flags.Delay)
+				}
+
+				if url != "" {
+					if reused, err = flags.focusOpenTab(url); err != nil {
+						return err
+					} else if reused {
+						continue
+					}
+				}
+
+				if _, err := runApplescript(tabCreateScript(url, flags.Window, flags.Space, cmd.Flags().Changed("space"), flags.LittleArc, flags.Background)); err != nil {
+					return err
+				}
+			}
+
+			if len(args) == 0 && (flags.Clipboard || !isatty.IsTerminal(os.Stdin.Fd())) {
+				infof(cmd, "opened %d tabs\n", len(urls))
+			}
+
+			window := flags.Window
+			if flags.LittleArc || reused {
+				window = 0
+			}
+
+			return flags.waitForTab(cmd, window, 0)
 		},
 	}
 
 	cmd.Flags().BoolVar(&flags.LittleArc, "little", false, "open in little arc")
 	cmd.Flags().IntVar(&flags.Space, "space", 0, "space to create tab in")
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window to create tab in (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().BoolVar(&flags.Background, "background", false, "create the tab without selecting it")
+	cmd.Flags().DurationVar(&flags.Delay, "delay", 0, "delay between opening two urls read from stdin")
+	cmd.Flags().BoolVar(&flags.Clipboard, "clipboard", false, "open the urls copied to the clipboard when no url is given")
+	addWaitFlags(cmd, &flags.waitFlags)
+	cmd.MarkFlagsMutuallyExclusive("wait", "background")
+	addReuseFlags(cmd, &flags.reuseFlags)
+	cmd.MarkFlagsMutuallyExclusive("reuse", "little")
+	// selecting a reused tab brings its window to the front, which changes the window the next urls open in
+	cmd.MarkFlagsMutuallyExclusive("reuse", "window")
 	return cmd
 }
 
+// reuseFlags holds the flags of the commands selecting an already open tab instead of opening a url again.
+type reuseFlags struct {
+	Reuse      bool
+	ReuseMatch string
+}
+
+func addReuseFlags(cmd *cobra.Command, flags *reuseFlags) {
+	cmd.Flags().BoolVar(&flags.Reuse, "reuse", false, "select the tab already showing the url if any, instead of opening a new one")
+	cmd.Flags().StringVar(&flags.ReuseMatch, "reuse-match", "exact", "how urls are compared with --reuse, one of exact, prefix or host")
+	cmd.RegisterFlagCompletionFunc("reuse-match", cobra.FixedCompletions([]string{"exact", "prefix", "host"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// focusOpenTab selects the first tab showing the url if --reuse is set, and reports whether one was found.
+func (f reuseFlags) focusOpenTab(url string) (bool, error) {
+	if !f.Reuse {
+		return false, nil
+	}
+
+	switch f.ReuseMatch {
+	case "exact", "prefix", "host":
+	default:
+		return false, fmt.Errorf("invalid reuse match %q, must be one of exact, prefix or host", f.ReuseMatch)
+	}
+
+	tabs, err := listTabs()
+	if err != nil {
+		return false, err
+	}
+
+	for _, tab := range tabs {
+		if !urlMatches(tab.URL, url, f.ReuseMatch) {
+			continue
+		}
+
+		if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+			tell window %d to tell (first tab whose id is "%s") to select
+			set index of window %[1]d to 1
+			%s
+		end tell`, tab.WindowID, escapeApplescript(tab.ID), activateStatement())); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// urlMatches compares the url of a tab with a url given on the command line, either exactly, as a
+// prefix, or by host. Arc adds a scheme to the urls it opens, so https is assumed when url has none,
+// and a trailing slash is ignored.
+func urlMatches(tabURL string, url string, match string) bool {
+	if !urlSchemeRegexp.MatchString(url) {
+		url = "https://" + url
+	}
+
+	switch match {
+	case "prefix":
+		return strings.HasPrefix(tabURL, url)
+	case "host":
+		tabU, err := neturl.Parse(tabURL)
+		if err != nil {
+			return false
+		}
+
+		u, err := neturl.Parse(url)
+		if err != nil {
+			return false
+		}
+
+		return u.Host != "" && strings.EqualFold(tabU.Host, u.Host)
+	default:
+		return strings.TrimSuffix(tabURL, "/") == strings.TrimSuffix(url, "/")
+	}
+}
+
+// tabCreateScript returns the script creating a tab for the given url, or a blank tab if url is empty.
+func tabCreateScript(url string, window int, space int, inSpace bool, littleArc bool, background bool) string {
+	makeTab := "make new tab"
+	if url != "" {
+		makeTab = fmt.Sprintf(`make new tab with properties {URL:"%s"}`, escapeApplescript(url))
+	}
+
+	if littleArc {
+		return fmt.Sprintf(`tell application "Arc" to %s`, makeTab)
+	}
+
+	if inSpace {
+		makeTab = fmt.Sprintf(`tell space %d to %s`, space, makeTab)
+	}
+
+	if background {
+		return fmt.Sprintf(`tell application "Arc"
+			tell %s
+				set previousTab to active tab
+				%s
+				tell previousTab to select
+			end tell
+		end tell`, windowSpecifier(window), makeTab)
+	}
+
+	return fmt.Sprintf(`tell application "Arc"
+		tell %s
+			%s
+		end tell
+		%s
+	end tell`, windowSpecifier(window), makeTab, activateStatement())
+}
+
 func NewCmdTabFocus() *cobra.Command {
+	var flags struct {
+		URL bool
+	}
+
 	cmd := &cobra.Command{
-		Use:   "focus <tab-id>",
-		Short: "Select a tab by id",
-		Args:  cobra.ExactArgs(1),
+		Use:               "focus <tab-id|substring>",
+		Short:             "Select a tab by id or title",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeTabIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			property := "title"
+			if flags.URL {
+				property = "URL"
+			}
 
-			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
-    if (count of windows) is 0 then
-    make new window
-  end if
-      set tabIndex to 1
-      repeat with aTab in every tab of first window
-        if id of aTab is "%s" then
-          tell tab tabIndex of window 1 to select
-          activate
-          return tabIndex
-        end if
-        set tabIndex to tabIndex + 1
-      end repeat
-    end tell`, args[0])); err != nil {
+			output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+	repeat with windowIndex from 1 to count of windows
+		set tabIndex to 1
+		repeat with aTab in every tab of window windowIndex
+			try
+				ignoring case
+					if id of aTab is "%[1]s" or %[2]s of aTab contains "%[1]s" then
+						tell tab tabIndex of window windowIndex to select
+						set index of window windowIndex to 1
+						%[3]s
+						return "found"
+					end if
+				end ignoring
+			end try
+			set tabIndex to tabIndex + 1
+		end repeat
+	end repeat
+	return "not_found"
+end tell`, escapeApplescript(args[0]), property, activateStatement()))
+			if err != nil {
 				return err
 			}
 
+			if strings.TrimSpace(string(output)) == "not_found" {
+				return notFoundf("no tab found with %s containing %q", strings.ToLower(property), args[0])
+			}
+
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&flags.URL, "url", false, "match on the url instead of the title")
 	return cmd
 }
 
 //go:embed applescript/list-tabs.applescript
 var listTabsScript string
 
-func NewCmdTabList() *cobra.Command {
-	var flags struct {
-		Pinned   bool
-		Favorite bool
-		Unpinned bool
-		Json     bool
+func listTabs() ([]Tab, error) {
+	output, err := runApplescript(listTabsScript)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := &cobra.Command{
-		Use:     "list",
-		Aliases: []string{"ls"},
-		Short:   `List tabs`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			output, err := runApplescript(listTabsScript)
-			if err != nil {
-				return err
-			}
-
-			var tabs []Tab
-			if err := json.Unmarshal(output, &tabs); err != nil {
-				return err
-			}
+	var tabs []Tab
+	if err := json.Unmarshal(output, &tabs); err != nil {
+		return nil, err
+	}
 
-			var filteredTabs []Tab
-			if !flags.Pinned && !flags.Unpinned && !flags.Favorite {
-				filteredTabs = tabs
-			} else {
-				for _, tab := range tabs {
-					if flags.Pinned && tab.Location == "pinned" {
-						filteredTabs = append(filteredTabs, tab)
-					}
+	return tabs, nil
+}
 
-					if flags.Unpinned && tab.Location == "unpinned" {
-						filteredTabs = append(filteredTabs, tab)
-					}
+//go:embed applescript/list-active-tabs.applescript
+var listActiveTabsScript string
 
-					if flags.Favorite && tab.Location == "topApp" {
-						filteredTabs = append(filteredTabs, tab)
-					}
-				}
-			}
+// listActiveTabs returns the active tab of every window, without reading the other tabs.
+func listActiveTabs() ([]Tab, error) {
+	output, err := runApplescript(listActiveTabsScript)
+	if err != nil {
+		return nil, err
+	}
 
-			sort.SliceStable(filteredTabs, func(i, j int) bool {
-				if filteredTabs[i].State() == filteredTabs[j].State() {
-					return filteredTabs[i].ID < filteredTabs[j].ID
-				}
+	var tabs []Tab
+	if err := json.Unmarshal(output, &tabs); err != nil {
+		return nil, err
+	}
 
-				if filteredTabs[i].State() == TabStateFavorite {
-					return true
-				}
+	return tabs, nil
+}
 
-				if filteredTabs[j].State() == TabStateFavorite {
-					return false
-				}
+// tabSpecifier returns the applescript reference of the tab with the given id,
+// relative to its window, or of the active tab when id is 0.
+func tabSpecifier(id int) string {
+	if id == 0 {
+		return "active tab"
+	}
 
-				if filteredTabs[i].State() == TabStatePinned {
-					return true
-				}
+	return fmt.Sprintf("tab %d", id)
+}
 
-				return false
-			})
+// executeJavascript runs javascript in a tab, and returns its result. Arc only
+// allows it when "Allow JavaScript from Apple Events" is enabled in its
+// Developer menu.
+func executeJavascript(window int, tab int, javascript string) ([]byte, error) {
+	output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+		tell %s
+			tell %s
+				execute javascript "%s"
+			end tell
+		end tell
+	end tell`, windowSpecifier(window), tabSpecifier(tab), escapeApplescript(javascript)))
+	if err != nil {
+		var applescriptError *AppleScriptError
+		if errors.As(err, &applescriptError) && strings.Contains(applescriptError.Stderr, "JavaScript") && strings.Contains(applescriptError.Stderr, "turned off") {
+			return nil, fmt.Errorf("javascript from apple events is disabled, enable it in Arc with View > Developer > Allow JavaScript from Apple Events")
+		}
+
+		return nil, err
+	}
 
-			if flags.Json {
-				encoder := json.NewEncoder(os.Stdout)
-				encoder.SetIndent("", "  ")
-				encoder.SetEscapeHTML(false)
-				return encoder.Encode(filteredTabs)
-			}
+	return output, nil
+}
 
-			var printer tableprinter.TablePrinter
-			if !isatty.IsTerminal(os.Stdout.Fd()) {
-				printer = tableprinter.New(os.Stdout, false, 0)
-			} else {
-				w, _, err := term.GetSize(int(os.Stdout.Fd()))
-				if err != nil {
-					return err
-				}
+const audibleJavascript = `Array.from(document.querySelectorAll("audio, video")).some((media) => !media.paused && !media.muted && media.volume > 0)`
 
-				printer = tableprinter.New(os.Stdout, true, w)
-			}
+// tabIsAudible reports whether a tab is playing sound, based on the state of its audio and video elements.
+func tabIsAudible(tab Tab) bool {
+	output, err := executeJavascript(tab.WindowID, tab.Index, audibleJavascript)
+	if err != nil {
+		return false
+	}
 
-			printer.AddHeader([]string{"ID", "State", "Title", "URL"})
-			for _, tab := range filteredTabs {
-				printer.AddField(tab.ID)
-				printer.AddField(string(tab.State()))
-				printer.AddField(tab.Title)
-				printer.AddField(tab.URL)
-				printer.EndRow()
-			}
+	return strings.TrimSpace(string(output)) == "true"
+}
 
-			return printer.Render()
-		},
-	}
+// TabInfo holds the details of a single tab printed by tab info.
+type TabInfo struct {
+	Tab     `yaml:",inline"`
+	Loading bool `json:"loading" yaml:"loading"`
+	Audible bool `json:"audible" yaml:"audible"`
+}
 
-	cmd.Flags().BoolVar(&flags.Json, "json", false, "output as json")
-	cmd.Flags().BoolVar(&flags.Pinned, "pinned", false, "only show pinned tabs")
-	cmd.Flags().BoolVar(&flags.Unpinned, "unpinned", false, "only show unpinned tabs")
-	cmd.Flags().BoolVar(&flags.Favorite, "favorite", false, "only show favorite tabs")
-	return cmd
+func (t TabInfo) porcelainRow() []string {
+	return append(t.Tab.porcelainRow(), strconv.FormatBool(t.Loading), strconv.FormatBool(t.Audible))
 }
 
-func NewCmdTabClose() *cobra.Command {
+func NewCmdTabInfo() *cobra.Command {
+	var flags struct {
+		outputFlags
+		Window int
+		Active bool
+	}
+
 	cmd := &cobra.Command{
-		Use:     "close",
-		Aliases: []string{"remove", "rm"},
-		Short:   "Close a tab",
-		Args:    cobra.MaximumNArgs(1),
+		Use:   "info [tab-id]",
+		Short: "Print the details of a tab",
+		Long: `Print the details of a tab: its window, id, title, url, and whether it is loading, pinned and
+playing sound.
+
+The audible state is read from the audio and video elements of the page, which requires Arc to allow
+javascript from apple events; the tab is reported as silent otherwise.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeTabIndexes),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				if _, err := runApplescript(`tell application "Arc"
-					tell front window
-						tell active tab to close
-					end tell
-				end tell`); err != nil {
-					return err
-				}
-				return nil
+			if len(args) == 0 && !flags.Active {
+				return errors.New("either a tab id or --active must be given")
 			}
 
-			for _, arg := range args {
-				tabID, err := strconv.Atoi(arg)
+			if len(args) > 0 && flags.Active {
+				return errors.New("a tab id cannot be given with --active")
+			}
+
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			var index int
+			if flags.Active {
+				active, err := getActiveTab(flags.Window)
 				if err != nil {
 					return err
 				}
-				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
-					tell front window
-				  		tell tab %d to close
-					end tell
-			  	end tell`, tabID)); err != nil {
+				index = active.Index
+			} else {
+				var err error
+				if index, err = strconv.Atoi(args[0]); err != nil {
 					return err
 				}
 			}
 
-			return nil
-		},
-	}
-
-	return cmd
-}
+			tabs, err := listTabs()
+			if err != nil {
+				return err
+			}
 
-func NewCmdTabReload() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "reload",
-		Short: `Reload a tab"`,
-		Args:  cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				if _, err := runApplescript(`tell application "Arc"
-				tell front window
-				  tell active tab to reload
-				end tell
-			end tell`); err != nil {
-					return err
+			var info TabInfo
+			for _, tab := range tabs {
+				if tab.WindowID == windowID && tab.Index == index {
+					info.Tab = tab
 				}
+			}
 
-				return nil
+			if info.ID == "" {
+				return notFoundf("no tab found with id %d in window %d", index, windowID)
 			}
 
-			tabID, err := strconv.Atoi(args[0])
+			output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell window %d
+					return loading of tab %d
+				end tell
+			end tell`, windowID, index))
 			if err != nil {
 				return err
 			}
+			info.Loading = strings.TrimSpace(string(output)) == "true"
+			info.Audible = tabIsAudible(info.Tab)
 
-			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
-			tell front window
-			  tell tab %d to reload
-			end tell
-		  end tell`, tabID)); err != nil {
+			if ok, err := printItem(cmd.OutOrStdout(), flags.outputFlags, info); ok || err != nil {
 				return err
 			}
 
-			return nil
+			return printKeyValues(cmd.OutOrStdout(), [][2]string{
+				{"Window", strconv.Itoa(info.WindowID)},
+				{"Index", strconv.Itoa(info.Index)},
+				{"ID", info.ID},
+				{"Title", info.Title},
+				{"URL", info.URL},
+				{"State", string(info.State())},
+				{"Loading", strconv.FormatBool(info.Loading)},
+				{"Pinned", strconv.FormatBool(info.Pinned)},
+				{"Audible", strconv.FormatBool(info.Audible)},
+			})
 		},
 	}
 
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().BoolVar(&flags.Active, "active", false, "show the active tab")
+	addOutputFlags(cmd, &flags.outputFlags)
 	return cmd
 }
 
-func NewCmdTabExecute() *cobra.Command {
+func findTab(tabs []Tab, tabID string) (Tab, error) {
+	for _, tab := range tabs {
+		if tab.ID == tabID {
+			return tab, nil
+		}
+	}
+
+	return Tab{}, notFoundf("no tab found with id %q", tabID)
+}
+
+func NewCmdTabSearch() *cobra.Command {
 	var flags struct {
-		Eval string
+		TitleOnly bool
+		URLOnly   bool
+		Regex     bool
+		Action    string
+		ToWindow  int
+		Yes       bool
+		outputFlags
 	}
 
 	cmd := &cobra.Command{
-		Use:   "exec <script>",
-		Short: "Execute javascript in the active tab",
-		Args:  cobra.MaximumNArgs(1),
+		Use:   "search <term>",
+		Short: "Search the tabs of every window by title and url",
+		Long: `Search the tabs of every window by title and url.
+
+The term is matched case-insensitively, as a substring or, with --regex, as a Go regular expression.
+
+With --action, the matching tabs are closed, reloaded or moved to the window given by --to-window
+instead of being printed. Closing and moving tabs asks for confirmation, unless --yes is set; when not
+run in a terminal, --yes is required.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var javascript string
-			if cmd.Flags().Changed("eval") {
-				javascript = escapeJavascript(flags.Eval)
-			} else if !isatty.IsTerminal(os.Stdin.Fd()) {
-				content, err := io.ReadAll(os.Stdin)
-				if err != nil {
-					return err
-				}
-				if len(content) == 0 {
-					return fmt.Errorf("no javascript provided")
+			switch flags.Action {
+			case "", "close", "reload":
+			case "move":
+				if !cmd.Flags().Changed("to-window") {
+					return fmt.Errorf("--to-window is required to move tabs")
 				}
+			default:
+				return fmt.Errorf("invalid action %q, must be one of close, reload or move", flags.Action)
+			}
 
-				javascript = escapeJavascript(string(content))
-			} else {
-				return fmt.Errorf("no javascript provided")
+			match := func(s string) bool {
+				return strings.Contains(strings.ToLower(s), strings.ToLower(args[0]))
 			}
 
-			var osascript string
-			if len(args) == 0 {
-				osascript = fmt.Sprintf(`tell application "Arc"
-				tell front window
-				  tell active tab
-				  	execute javascript "%s"
-				  end tell
-				end tell
-			  end tell`, javascript)
-			} else {
-				tabID, err := strconv.Atoi(args[0])
+			if flags.Regex {
+				re, err := regexp.Compile("(?i)" + args[0])
 				if err != nil {
-					return err
+					return fmt.Errorf("invalid regular expression: %w", err)
 				}
-				osascript = fmt.Sprintf(`tell application "Arc"
-				tell front window
-				  tell tab %d
-				  	execute javascript "%s"
-				  end tell
-				end tell
-			  end tell`, tabID, javascript)
+				match = re.MatchString
 			}
 
-			output, err := runApplescript(osascript)
+			tabs, err := listTabs()
 			if err != nil {
 				return err
 			}
 
-			if (len(output)) > 0 {
-				cmd.Print(string(output))
+			var matchingTabs []Tab
+			for _, tab := range tabs {
+				if (!flags.URLOnly && match(tab.Title)) || (!flags.TitleOnly && match(tab.URL)) {
+					matchingTabs = append(matchingTabs, tab)
+				}
 			}
 
-			return nil
-		},
+			if flags.Action != "" {
+				if len(matchingTabs) == 0 {
+					infof(cmd, "no tab matches %q\n", args[0])
+					return nil
+				}
+
+				if flags.Action != "reload" && !flags.Yes {
+					if !canPrompt() {
+						return fmt.Errorf("--yes is required to %s tabs when not running in a terminal", flags.Action)
+					}
+
+					if ok, err := confirm(cmd, fmt.Sprintf("%s %d tabs?", flags.Action, len(matchingTabs))); !ok || err != nil {
+						return err
+					}
+				}
+
+				if _, err := runApplescript(tabBatchScript(flags.Action, matchingTabs, flags.ToWindow)); err != nil {
+					return err
+				}
+
+				past := map[string]string{"close": "closed", "reload": "reloaded", "move": "moved"}
+				infof(cmd, "%s %d tabs\n", past[flags.Action], len(matchingTabs))
+				return nil
+			}
+
+			if ok, err := printItems(cmd.OutOrStdout(), flags.outputFlags, matchingTabs); ok || err != nil {
+				return err
+			}
+
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			printer.AddHeader([]string{"Window", "Tab", "Title", "URL"})
+			for _, tab := range matchingTabs {
+				printer.AddField(strconv.Itoa(tab.WindowID))
+				printer.AddField(strconv.Itoa(tab.Index))
+				printer.AddField(tab.Title)
+				printer.AddField(tab.URL)
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.TitleOnly, "title-only", false, "only match the titles")
+	cmd.Flags().BoolVar(&flags.URLOnly, "url-only", false, "only match the urls")
+	cmd.Flags().BoolVar(&flags.Regex, "regex", false, "interpret the term as a regular expression")
+	cmd.MarkFlagsMutuallyExclusive("title-only", "url-only")
+	cmd.Flags().StringVar(&flags.Action, "action", "", "act on the matching tabs, one of close, reload or move")
+	cmd.RegisterFlagCompletionFunc("action", cobra.FixedCompletions([]string{"close", "reload", "move"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().IntVar(&flags.ToWindow, "to-window", 0, "window to move the matching tabs to, with --action move")
+	cmd.RegisterFlagCompletionFunc("to-window", completeWindowIDs)
+	cmd.Flags().BoolVarP(&flags.Yes, "yes", "y", false, "do not ask for confirmation before closing or moving tabs")
+	addOutputFlags(cmd, &flags.outputFlags)
+	return cmd
+}
+
+// tabBatchScript returns an applescript applying an action to tabs of any window. Tabs are referenced
+// by their arc id, which unlike their index is not shifted by closing the other tabs.
+func tabBatchScript(action string, tabs []Tab, toWindow int) string {
+	var statements []string
+	for _, tab := range tabs {
+		id := escapeApplescript(tab.ID)
+		switch action {
+		case "close":
+			statements = append(statements, fmt.Sprintf(`tell window %d to close (first tab whose id is "%s")`, tab.WindowID, id))
+		case "reload":
+			statements = append(statements, fmt.Sprintf(`tell window %d to tell (first tab whose id is "%s") to reload`, tab.WindowID, id))
+		case "move":
+			// arc cannot move tabs, see NewCmdTabMove
+			statements = append(statements, fmt.Sprintf(`tell window %d to make new tab with properties {URL:"%s"}`, toWindow, escapeApplescript(tab.URL)))
+			statements = append(statements, fmt.Sprintf(`tell window %d to close (first tab whose id is "%s")`, tab.WindowID, id))
+		}
+	}
+
+	return fmt.Sprintf(`tell application "Arc"
+		%s
+	end tell`, strings.Join(statements, "\n"))
+}
+
+func NewCmdTabExport() *cobra.Command {
+	var flags struct {
+		Format string
+		Window int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export tabs as a list of links",
+		Long: `Export tabs as a list of links, formatted as markdown, html or json.
+
+Unlike session save, the links are meant to be pasted into notes or documents.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tabs, err := listTabs()
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("window") {
+				var windowTabs []Tab
+				for _, tab := range tabs {
+					if tab.WindowID == flags.Window {
+						windowTabs = append(windowTabs, tab)
+					}
+				}
+				tabs = windowTabs
+			}
+
+			w := cmd.OutOrStdout()
+			switch flags.Format {
+			case "markdown":
+				for _, tab := range tabs {
+					fmt.Fprintf(w, "- %s\n", markdownLink(tab))
+				}
+			case "html":
+				fmt.Fprintln(w, "<ul>")
+				for _, tab := range tabs {
+					fmt.Fprintf(w, "  <li><a href=\"%s\">%s</a></li>\n", html.EscapeString(tab.URL), html.EscapeString(tab.Title))
+				}
+				fmt.Fprintln(w, "</ul>")
+			case "json":
+				return encodeItems(w, "json", tabs)
+			default:
+				return fmt.Errorf("invalid format %q, must be one of markdown, html or json", flags.Format)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.Format, "format", "markdown", "format of the links, one of markdown, html or json")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"markdown", "html", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "only export tabs of this window")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	return cmd
+}
+
+var (
+	markdownTitleReplacer = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+	markdownURLReplacer   = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20")
+)
+
+// markdownLink formats a tab as a markdown link.
+func markdownLink(tab Tab) string {
+	return fmt.Sprintf("[%s](%s)", markdownTitleReplacer.Replace(tab.Title), markdownURLReplacer.Replace(tab.URL))
+}
+
+func NewCmdTabCopyURL() *cobra.Command {
+	var flags struct {
+		Window int
+		All    bool
+		Format string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "copy-url",
+		Short: "Copy the url of the active tab, or of every tab, to the clipboard",
+		Long: `Copy the url of the active tab to the clipboard, or with --all the urls of every tab, one per line.
+
+--window restricts --all to the tabs of a window. With --format markdown, markdown links are copied
+instead of bare urls.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Format != "plain" && flags.Format != "markdown" {
+				return fmt.Errorf("invalid format %q, must be one of plain or markdown", flags.Format)
+			}
+
+			var tabs []Tab
+			if flags.All {
+				allTabs, err := listTabs()
+				if err != nil {
+					return err
+				}
+
+				for _, tab := range allTabs {
+					if !cmd.Flags().Changed("window") || tab.WindowID == flags.Window {
+						tabs = append(tabs, tab)
+					}
+				}
+			} else {
+				tab, err := getActiveTab(flags.Window)
+				if err != nil {
+					return err
+				}
+				tabs = []Tab{tab}
+			}
+
+			var lines []string
+			for _, tab := range tabs {
+				if flags.Format == "markdown" {
+					lines = append(lines, "- "+markdownLink(tab))
+				} else {
+					lines = append(lines, tab.URL)
+				}
+			}
+
+			if err := copyToClipboard(strings.Join(lines, "\n")); err != nil {
+				return err
+			}
+
+			infof(cmd, "copied %d urls\n", len(lines))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tabs (defaults to the front window, or every window with --all)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().BoolVar(&flags.All, "all", false, "copy the urls of every tab")
+	cmd.Flags().StringVar(&flags.Format, "format", "plain", "format of the copied urls, one of plain or markdown")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"plain", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func NewCmdTabOpenTemplate() *cobra.Command {
+	var flags struct {
+		Window int
+		Params map[string]string
+	}
+
+	cmd := &cobra.Command{
+		Use:     "open-template <name> [value...]",
+		Aliases: []string{"new-from-template"},
+		Short:   "Open a url template of the config file in a new tab",
+		Long: `Open a url template of the config file in a new tab.
+
+Templates are defined under the templates key of the config file:
+
+    templates:
+      jira: https://jira.example.com/browse/{}
+      search: https://github.com/{owner}/{repo}/issues?q={}
+
+Each {} placeholder is replaced by the next value given as argument, and each {name} placeholder by
+the value given with --param name=value. Values are escaped for use in urls.`,
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			templates, err := urlTemplates()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			var completions []string
+			for name, template := range templates {
+				completions = append(completions, fmt.Sprintf("%s\t%s", name, template))
+			}
+			sort.Strings(completions)
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templates, err := urlTemplates()
+			if err != nil {
+				return err
+			}
+
+			template, ok := templates[args[0]]
+			if !ok {
+				var names []string
+				for name := range templates {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				return notFoundf("no template named %q in %s, available templates: %s", args[0], configPath(), strings.Join(names, ", "))
+			}
+
+			url, err := expandURLTemplate(template, args[1:], flags.Params)
+			if err != nil {
+				return fmt.Errorf("template %s: %w", args[0], err)
+			}
+
+			if _, err := runApplescript(tabCreateScript(url, flags.Window, 0, false, false, false)); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window to create tab in (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().StringToStringVar(&flags.Params, "param", nil, "value of a named placeholder, as name=value (can be repeated)")
+	return cmd
+}
+
+var urlPlaceholderRegexp = regexp.MustCompile(`\{([A-Za-z0-9_-]*)\}`)
+
+// expandURLTemplate replaces the {} placeholders of a template by the values, in order, and the
+// {name} placeholders by the params. Values are query escaped after the ? of the template, and path
+// escaped before it.
+func expandURLTemplate(template string, values []string, params map[string]string) (string, error) {
+	var err error
+	next := 0
+	query := strings.Index(template, "?")
+	var b strings.Builder
+	last := 0
+	for _, match := range urlPlaceholderRegexp.FindAllStringSubmatchIndex(template, -1) {
+		b.WriteString(template[last:match[0]])
+		last = match[1]
+
+		var value string
+		if name := template[match[2]:match[3]]; name != "" {
+			var ok bool
+			value, ok = params[name]
+			if !ok && err == nil {
+				err = fmt.Errorf("missing value for {%s}, set it with --param %s=value", name, name)
+			}
+		} else {
+			if next < len(values) {
+				value = values[next]
+			}
+			next++
+		}
+
+		if query >= 0 && match[0] > query {
+			b.WriteString(neturl.QueryEscape(value))
+		} else {
+			b.WriteString(neturl.PathEscape(value))
+		}
+	}
+	b.WriteString(template[last:])
+	url := b.String()
+
+	if err != nil {
+		return "", err
+	}
+
+	if next != len(values) {
+		return "", fmt.Errorf("expected %d values, got %d", next, len(values))
+	}
+
+	return url, nil
+}
+
+func NewCmdTabImport() *cobra.Command {
+	var flags struct {
+		NewWindow bool
+		Space     string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Open every link of a file in a new tab",
+		Long: `Open every link of a file in a new tab, use - to read stdin.
+
+The file can be a markdown list of links, as written by tab export, a plain list of urls, one per line,
+or json: an array of urls, of tabs, or of windows with their tabs, as written by session save. The
+format is chosen from the file extension, or detected from the content.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var content []byte
+			var err error
+			if args[0] == "-" {
+				content, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				content, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			urls, err := parseLinks(args[0], content)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", args[0], err)
+			}
+
+			if len(urls) == 0 {
+				return fmt.Errorf("no link found in %s", args[0])
+			}
+
+			var space Space
+			if flags.Space != "" {
+				if space, err = findSpace(flags.Space); err != nil {
+					return err
+				}
+			}
+
+			if flags.NewWindow {
+				if _, err := runApplescript(`tell application "Arc" to make new window`); err != nil {
+					return err
+				}
+			}
+
+			for _, url := range urls {
+				if _, err := runApplescript(tabCreateScript(url, 0, space.ID, flags.Space != "", false, false)); err != nil {
+					return err
+				}
+			}
+
+			infof(cmd, "opened %d tabs\n", len(urls))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.NewWindow, "new-window", false, "open the links in a new window")
+	cmd.Flags().StringVar(&flags.Space, "space", "", "open the links in this space, by name or index")
+	return cmd
+}
+
+var markdownLinkRegexp = regexp.MustCompile(`^(?:[-*+]\s+)?\[.*\]\((\S+)\)$`)
+
+// parseLinks extracts the urls of a markdown list of links, a json document or a plain list of urls.
+func parseLinks(name string, content []byte) ([]string, error) {
+	format := "plain"
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		format = "markdown"
+	case ".json":
+		format = "json"
+	case ".txt":
+	default:
+		trimmed := bytes.TrimSpace(content)
+		if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') && json.Valid(trimmed) {
+			format = "json"
+		} else if bytes.Contains(content, []byte("](")) {
+			format = "markdown"
+		}
+	}
+
+	if format == "json" {
+		return parseJSONLinks(content)
+	}
+
+	var urls []string
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if format == "markdown" {
+			match := markdownLinkRegexp.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("line %d: expected a markdown link, got %q", i+1, line)
+			}
+			line = match[1]
+		} else if strings.ContainsAny(line, " \t") {
+			return nil, fmt.Errorf("line %d: invalid url %q", i+1, line)
+		}
+
+		urls = append(urls, line)
+	}
+
+	return urls, nil
+}
+
+// parseJSONLinks extracts the urls of a json array of urls, of tabs, or of windows with their tabs.
+func parseJSONLinks(content []byte) ([]string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(content, &items); err != nil {
+		var syntaxError *json.SyntaxError
+		if errors.As(err, &syntaxError) {
+			line := bytes.Count(content[:syntaxError.Offset], []byte("\n")) + 1
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		return nil, err
+	}
+
+	var urls []string
+	for i, item := range items {
+		var url string
+		if err := json.Unmarshal(item, &url); err == nil {
+			urls = append(urls, url)
+			continue
+		}
+
+		var object struct {
+			URL  string `json:"url"`
+			Tabs []Tab  `json:"tabs"`
+		}
+		if err := json.Unmarshal(item, &object); err != nil {
+			return nil, fmt.Errorf("item %d: expected a url, a tab or a window: %w", i+1, err)
+		}
+
+		if object.URL != "" {
+			urls = append(urls, object.URL)
+		}
+		for _, tab := range object.Tabs {
+			urls = append(urls, tab.URL)
+		}
+	}
+
+	return urls, nil
+}
+
+func NewCmdTabScroll() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+		To     string
+		By     int
+		Page   int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "scroll",
+		Short: "Scroll the page of a tab",
+		Long: `Scroll the page of a tab, either to its top or bottom with --to, by a number of pixels with --by, or
+by a number of viewport heights with --page. Negative values scroll up.
+
+The page is scrolled with javascript, which Arc only runs when "Allow JavaScript from Apple Events" is
+enabled in View > Developer.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var javascript string
+			switch {
+			case cmd.Flags().Changed("to"):
+				switch flags.To {
+				case "top":
+					javascript = "window.scrollTo(0, 0)"
+				case "bottom":
+					javascript = "window.scrollTo(0, document.documentElement.scrollHeight)"
+				default:
+					return fmt.Errorf("invalid position %q, must be one of top or bottom", flags.To)
+				}
+			case cmd.Flags().Changed("by"):
+				javascript = fmt.Sprintf("window.scrollBy(0, %d)", flags.By)
+			case cmd.Flags().Changed("page"):
+				javascript = fmt.Sprintf("window.scrollBy(0, %d * window.innerHeight)", flags.Page)
+			default:
+				return fmt.Errorf("one of --to, --by or --page must be set")
+			}
+
+			if _, err := executeJavascript(flags.Window, flags.ID, javascript); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().StringVar(&flags.To, "to", "", "scroll to the top or bottom of the page")
+	cmd.RegisterFlagCompletionFunc("to", cobra.FixedCompletions([]string{"top", "bottom"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().IntVar(&flags.By, "by", 0, "number of pixels to scroll by")
+	cmd.Flags().IntVar(&flags.Page, "page", 0, "number of viewport heights to scroll by")
+	cmd.MarkFlagsMutuallyExclusive("to", "by", "page")
+	return cmd
+}
+
+func NewCmdTabFind() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+		Next   bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "find <text>",
+		Short: "Find and select text in the page of a tab",
+		Long: `Find and select text in the page of a tab, case-insensitively, and scroll to it.
+
+The search starts from the top of the page, or after the current match with --next, and fails when the
+text is not found. It runs with javascript, which Arc only runs when "Allow JavaScript from Apple Events"
+is enabled in View > Developer.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text, err := json.Marshal(args[0])
+			if err != nil {
+				return err
+			}
+
+			javascript := fmt.Sprintf("window.find(%s, false, false, true)", text)
+			if !flags.Next {
+				javascript = "window.getSelection().removeAllRanges(); " + javascript
+			}
+
+			output, err := executeJavascript(flags.Window, flags.ID, javascript)
+			if err != nil {
+				return err
+			}
+
+			if strings.TrimSpace(string(output)) != "true" {
+				return notFoundf("%q was not found in the page", args[0])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().BoolVar(&flags.Next, "next", false, "find the match following the current one")
+	return cmd
+}
+
+func NewCmdTabList() *cobra.Command {
+	var flags struct {
+		Window     int
+		Pinned     bool
+		Favorite   bool
+		Unpinned   bool
+		Audible    bool
+		ActiveOnly bool
+		outputFlags
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   `List tabs of every window`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list := listTabs
+			if flags.ActiveOnly {
+				list = listActiveTabs
+			}
+
+			tabs, err := list()
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("window") {
+				var windowTabs []Tab
+				for _, tab := range tabs {
+					if tab.WindowID == flags.Window {
+						windowTabs = append(windowTabs, tab)
+					}
+				}
+				tabs = windowTabs
+			}
+
+			var filteredTabs []Tab
+			if !flags.Pinned && !flags.Unpinned && !flags.Favorite {
+				filteredTabs = tabs
+			} else {
+				for _, tab := range tabs {
+					if flags.Pinned && tab.Pinned {
+						filteredTabs = append(filteredTabs, tab)
+					}
+
+					if flags.Unpinned && tab.Location == "unpinned" {
+						filteredTabs = append(filteredTabs, tab)
+					}
+
+					if flags.Favorite && tab.Location == "topApp" {
+						filteredTabs = append(filteredTabs, tab)
+					}
+				}
+			}
+
+			if flags.Audible {
+				var audibleTabs []Tab
+				for _, tab := range filteredTabs {
+					if tabIsAudible(tab) {
+						audibleTabs = append(audibleTabs, tab)
+					}
+				}
+				filteredTabs = audibleTabs
+			}
+
+			sort.SliceStable(filteredTabs, func(i, j int) bool {
+				if filteredTabs[i].WindowID != filteredTabs[j].WindowID {
+					return filteredTabs[i].WindowID < filteredTabs[j].WindowID
+				}
+
+				if filteredTabs[i].State() == filteredTabs[j].State() {
+					return filteredTabs[i].ID < filteredTabs[j].ID
+				}
+
+				if filteredTabs[i].State() == TabStateFavorite {
+					return true
+				}
+
+				if filteredTabs[j].State() == TabStateFavorite {
+					return false
+				}
+
+				if filteredTabs[i].State() == TabStatePinned {
+					return true
+				}
+
+				return false
+			})
+
+			if ok, err := printItems(cmd.OutOrStdout(), flags.outputFlags, filteredTabs); ok || err != nil {
+				return err
+			}
+
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			printer.AddHeader([]string{"Window", "ID", "State", "Title", "URL"})
+			for _, tab := range filteredTabs {
+				printer.AddField(strconv.Itoa(tab.WindowID))
+				printer.AddField(tab.ID)
+				printer.AddField(string(tab.State()))
+				printer.AddField(tab.Title)
+				printer.AddField(tab.URL)
+				printer.EndRow()
+			}
+
+			return printer.Render()
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "only show tabs of this window")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	addOutputFlags(cmd, &flags.outputFlags)
+	cmd.Flags().BoolVar(&flags.Pinned, "pinned", false, "only show pinned tabs")
+	cmd.Flags().BoolVar(&flags.Unpinned, "unpinned", false, "only show unpinned tabs")
+	cmd.Flags().BoolVar(&flags.Favorite, "favorite", false, "only show favorite tabs")
+	cmd.Flags().BoolVar(&flags.Audible, "audible", false, "only show tabs playing sound (requires javascript from apple events)")
+	cmd.Flags().BoolVar(&flags.ActiveOnly, "active-only", false, "only show the active tab of each window")
+	return cmd
+}
+
+func NewCmdTabClose() *cobra.Command {
+	var flags struct {
+		Window      int
+		Match       string
+		Interactive bool
+		Duplicates  bool
+		IgnoreQuery bool
+		Stale       time.Duration
+		Others      bool
+		Left        bool
+		Right       bool
+		DryRun      bool
+	}
+
+	cmd := &cobra.Command{
+		Use:     "close [tab-id...]",
+		Aliases: []string{"remove", "rm"},
+		Short:   "Close a tab",
+		Long: `Close a tab.
+
+Arc does not expose when a tab was last used, so --stale relies on the last visit of the tab's url in
+the history instead: a tab left open on a page is considered idle since the page was loaded, and tabs
+whose url is not in the history are kept. Like Arc's automatic archiving, --stale only closes
+unpinned tabs, never pinned and favorite ones.
+
+--others, --left and --right close the tabs other than the active one, before it or after it, like
+the context menu of a tab. They leave the pinned and favorite tabs open too.`,
+		ValidArgsFunction: completeTabIndexes,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Others || flags.Left || flags.Right {
+				if len(args) > 0 {
+					return errors.New("tab ids cannot be given with --others, --left or --right")
+				}
+
+				active, err := getActiveTab(flags.Window)
+				if err != nil {
+					return err
+				}
+
+				tabs, err := listTabs()
+				if err != nil {
+					return err
+				}
+
+				var tabIDs []int
+				for _, tab := range tabs {
+					if tab.WindowID != active.WindowID || tab.Location != "unpinned" {
+						continue
+					}
+
+					if (tab.Index < active.Index && (flags.Left || flags.Others)) || (tab.Index > active.Index && (flags.Right || flags.Others)) {
+						if flags.DryRun {
+							fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%s\t%s\n", tab.WindowID, tab.Index, tab.Title, tab.URL)
+						}
+						tabIDs = append(tabIDs, tab.Index)
+					}
+				}
+
+				if flags.DryRun {
+					return nil
+				}
+
+				if len(tabIDs) == 0 {
+					infof(cmd, "no tab to close\n")
+					return nil
+				}
+
+				if err := closeTabIndexes(flags.Window, tabIDs); err != nil {
+					return err
+				}
+
+				infof(cmd, "closed %d tabs\n", len(tabIDs))
+				return nil
+			}
+
+			if flags.Duplicates || cmd.Flags().Changed("stale") {
+				tabs, err := listTabs()
+				if err != nil {
+					return err
+				}
+
+				var windowTabs []Tab
+				for _, tab := range tabs {
+					if !cmd.Flags().Changed("window") || tab.WindowID == flags.Window {
+						windowTabs = append(windowTabs, tab)
+					}
+				}
+
+				var closedTabs []Tab
+				if flags.Duplicates {
+					closedTabs = findDuplicateTabs(windowTabs, flags.IgnoreQuery)
+				} else if closedTabs, err = findStaleTabs(windowTabs, flags.Stale); err != nil {
+					return err
+				}
+
+				if flags.DryRun {
+					for _, tab := range closedTabs {
+						fmt.Fprintf(cmd.OutOrStdout(), "%d\t%d\t%s\t%s\n", tab.WindowID, tab.Index, tab.Title, tab.URL)
+					}
+					return nil
+				}
+
+				if len(closedTabs) == 0 {
+					infof(cmd, "no tab to close\n")
+					return nil
+				}
+
+				if _, err := runApplescript(tabBatchScript("close", closedTabs, 0)); err != nil {
+					return err
+				}
+
+				if flags.Duplicates {
+					for _, tab := range closedTabs {
+						infof(cmd, "closed duplicate of %s\n", tab.URL)
+					}
+				}
+				infof(cmd, "closed %d tabs\n", len(closedTabs))
+				return nil
+			}
+
+			if flags.Interactive && canPrompt() {
+				picked, err := pickTabs(flags.Window)
+				if err != nil {
+					return err
+				}
+
+				if len(picked) == 0 {
+					return nil
+				}
+				args = picked
+			}
+
+			if cmd.Flags().Changed("match") {
+				output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+					set closedCount to 0
+					tell %s
+						repeat with tabIndex from (count of tabs) to 1 by -1
+							ignoring case
+								if title of tab tabIndex contains "%s" then
+									tell tab tabIndex to close
+									set closedCount to closedCount + 1
+								end if
+							end ignoring
+						end repeat
+					end tell
+					return closedCount
+				end tell`, windowSpecifier(flags.Window), escapeApplescript(flags.Match)))
+				if err != nil {
+					return err
+				}
+
+				infof(cmd, "closed %s tabs\n", strings.TrimSpace(string(output)))
+				return nil
+			}
+
+			if len(args) == 0 {
+				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+					tell %s
+						tell active tab to close
+					end tell
+				end tell`, windowSpecifier(flags.Window))); err != nil {
+					return err
+				}
+				return nil
+			}
+
+			var tabIDs []int
+			for _, arg := range args {
+				tabID, err := strconv.Atoi(arg)
+				if err != nil {
+					return err
+				}
+				tabIDs = append(tabIDs, tabID)
+			}
+
+			return closeTabIndexes(flags.Window, tabIDs)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window to close tabs in (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().StringVar(&flags.Match, "match", "", "close every tab whose title contains this string")
+	cmd.Flags().BoolVarP(&flags.Interactive, "interactive", "i", false, "pick the tabs to close from a list, when run in a terminal")
+	cmd.MarkFlagsMutuallyExclusive("interactive", "match")
+	cmd.Flags().BoolVar(&flags.Duplicates, "duplicates", false, "close the tabs whose url is already open in a previous tab, in every window unless --window is set")
+	cmd.Flags().BoolVar(&flags.IgnoreQuery, "ignore-query", false, "ignore the query string when comparing urls, with --duplicates")
+	cmd.Flags().DurationVar(&flags.Stale, "stale", 0, "close the unpinned tabs whose url was last visited longer ago than this duration")
+	cmd.Flags().BoolVar(&flags.Others, "others", false, "close every tab but the active one")
+	cmd.Flags().BoolVar(&flags.Left, "left", false, "close the tabs before the active one")
+	cmd.Flags().BoolVar(&flags.Right, "right", false, "close the tabs after the active one")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "only print the tabs that would be closed, with --duplicates, --stale, --others, --left or --right")
+	cmd.MarkFlagsMutuallyExclusive("duplicates", "stale", "match", "interactive", "others")
+	cmd.MarkFlagsMutuallyExclusive("duplicates", "stale", "match", "interactive", "left")
+	cmd.MarkFlagsMutuallyExclusive("duplicates", "stale", "match", "interactive", "right")
+	cmd.MarkFlagsMutuallyExclusive("others", "left")
+	cmd.MarkFlagsMutuallyExclusive("others", "right")
+	return cmd
+}
+
+// closeTabIndexes closes the tabs of a window by id.
+func closeTabIndexes(window int, tabIDs []int) error {
+	// Close the highest ids first, so that closing a tab does not shift the ids of the remaining ones.
+	sort.Sort(sort.Reverse(sort.IntSlice(tabIDs)))
+	var closeTabs []string
+	for _, tabID := range tabIDs {
+		closeTabs = append(closeTabs, fmt.Sprintf("tell tab %d to close", tabID))
+	}
+
+	_, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+		tell %s
+			%s
+		end tell
+	end tell`, windowSpecifier(window), strings.Join(closeTabs, "\n")))
+	return err
+}
+
+// findStaleTabs returns the unpinned tabs whose url was last visited longer ago than idle,
+// according to the history database.
+func findStaleTabs(tabs []Tab, idle time.Duration) ([]Tab, error) {
+	db, cleanup, err := openHistoryDB(historyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	statement, err := db.Prepare("SELECT last_visit_time FROM urls WHERE url = ?")
+	if err != nil {
+		return nil, historyQueryError(err)
+	}
+	defer statement.Close()
+
+	threshold := chromiumTime(time.Now().Add(-idle))
+	var staleTabs []Tab
+	for _, tab := range tabs {
+		if tab.Location != "unpinned" {
+			continue
+		}
+
+		var lastVisit int64
+		if err := statement.QueryRow(tab.URL).Scan(&lastVisit); errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return nil, historyQueryError(err)
+		}
+
+		if lastVisit < threshold {
+			staleTabs = append(staleTabs, tab)
+		}
+	}
+
+	return staleTabs, nil
+}
+
+// findDuplicateTabs returns the tabs whose url is the same as the one of a previous tab, optionally
+// ignoring the query string.
+func findDuplicateTabs(tabs []Tab, ignoreQuery bool) []Tab {
+	seen := make(map[string]bool)
+	var duplicates []Tab
+	for _, tab := range tabs {
+		key := tab.URL
+		if ignoreQuery {
+			if u, err := neturl.Parse(tab.URL); err == nil {
+				u.RawQuery = ""
+				u.ForceQuery = false
+				key = u.String()
+			}
+		}
+
+		if seen[key] {
+			duplicates = append(duplicates, tab)
+			continue
+		}
+		seen[key] = true
+	}
+
+	return duplicates
+}
+
+// pickTabs lets the user pick tabs of a window, and returns their ids.
+func pickTabs(window int) ([]string, error) {
+	if window == 0 {
+		window = 1
+	}
+
+	tabs, err := listTabs()
+	if err != nil {
+		return nil, err
+	}
+
+	var windowTabs []Tab
+	var options []string
+	for _, tab := range tabs {
+		if tab.WindowID == window {
+			windowTabs = append(windowTabs, tab)
+			options = append(options, fmt.Sprintf("%s (%s)", tab.Title, tab.URL))
+		}
+	}
+
+	indexes, err := pickItems("Tabs to close", options)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, index := range indexes {
+		ids = append(ids, strconv.Itoa(windowTabs[index].Index))
+	}
+
+	return ids, nil
+}
+
+func NewCmdTabReload() *cobra.Command {
+	var flags struct {
+		Window     int
+		All        bool
+		AllWindows bool
+		Stagger    time.Duration
+		Hard       bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "reload [tab-id]",
+		Short: "Reload a tab",
+		Long: `Reload a tab.
+
+The --hard flag bypasses the cache by sending Cmd+Shift+R to Arc through System Events. This uses ui
+scripting (accessibility permission, see arc doctor).
+
+With --all-windows, every tab of every window is reloaded, waiting for --stagger between two tabs. A
+tab failing to reload is reported without stopping the others.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeTabIndexes),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("stagger") && !flags.AllWindows {
+				return fmt.Errorf("--stagger can only be used with --all-windows")
+			}
+
+			if flags.AllWindows {
+				if len(args) > 0 {
+					return fmt.Errorf("a tab id cannot be given with --all-windows")
+				}
+
+				tabs, err := listTabs()
+				if err != nil {
+					return err
+				}
+
+				failed := 0
+				for i, tab := range tabs {
+					if i > 0 {
+						time.Sleep(flags.Stagger)
+					}
+
+					if _, err := runApplescript(tabBatchScript("reload", []Tab{tab}, 0)); err != nil {
+						cmd.PrintErrf("failed to reload tab %d of window %d %q: %s\n", tab.Index, tab.WindowID, tab.Title, err)
+						failed++
+					}
+				}
+
+				infof(cmd, "reloaded %d tabs\n", len(tabs)-failed)
+				if failed > 0 {
+					return fmt.Errorf("failed to reload %d tabs", failed)
+				}
+
+				return nil
+			}
+
+			// the tab to reload, as an applescript reference relative to the window
+			target := "active tab"
+			if len(args) > 0 {
+				tabID, err := strconv.Atoi(args[0])
+				if err != nil {
+					return err
+				}
+				target = fmt.Sprintf("tab %d", tabID)
+			}
+
+			var osascript string
+			if flags.Hard {
+				selectTabs := fmt.Sprintf(`tell %s to select
+					delay 0.2
+					tell application "System Events" to keystroke "r" using {command down, shift down}`, target)
+				if flags.All {
+					selectTabs = `repeat with tabIndex from 1 to tabCount
+						tell tab tabIndex to select
+						delay 0.2
+						tell application "System Events" to keystroke "r" using {command down, shift down}
+					end repeat`
+				}
+
+				osascript = fmt.Sprintf(`tell application "Arc"
+				set index of %s to 1
+				activate
+				tell front window
+					set tabCount to count of tabs
+					set previousTab to active tab
+					%s
+					tell previousTab to select
+				end tell
+			end tell
+			if %t then return tabCount
+			return 1`, windowSpecifier(flags.Window), selectTabs, flags.All)
+			} else if flags.All {
+				osascript = fmt.Sprintf(`tell application "Arc"
+				tell %s
+					set tabCount to count of tabs
+					repeat with tabIndex from 1 to tabCount
+						tell tab tabIndex to reload
+					end repeat
+					return tabCount
+				end tell
+			end tell`, windowSpecifier(flags.Window))
+			} else {
+				osascript = fmt.Sprintf(`tell application "Arc"
+				tell %s
+					tell %s to reload
+				end tell
+			end tell
+			return 1`, windowSpecifier(flags.Window), target)
+			}
+
+			output, err := runApplescript(osascript)
+			if err != nil {
+				return err
+			}
+
+			infof(cmd, "reloaded %s tabs\n", strings.TrimSpace(string(output)))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window to reload tabs in (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().BoolVar(&flags.All, "all", false, "reload every tab of the window")
+	cmd.Flags().BoolVar(&flags.AllWindows, "all-windows", false, "reload every tab of every window")
+	cmd.Flags().DurationVar(&flags.Stagger, "stagger", 0, "delay between reloading two tabs, with --all-windows")
+	cmd.Flags().BoolVar(&flags.Hard, "hard", false, "bypass the cache, using ui scripting")
+	cmd.MarkFlagsMutuallyExclusive("all-windows", "all")
+	cmd.MarkFlagsMutuallyExclusive("all-windows", "window")
+	cmd.MarkFlagsMutuallyExclusive("all-windows", "hard")
+	return cmd
+}
+
+func NewCmdTabRestore() *cobra.Command {
+	var flags struct {
+		Count int
+	}
+
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Aliases: []string{"reopen"},
+		Short:   "Reopen the last closed tab",
+		Long: `Reopen the last closed tab.
+
+Arc's scripting dictionary does not keep the closed tabs, so Cmd+Shift+T is sent to Arc through System
+Events. This uses ui scripting (accessibility permission, see arc doctor). Like the shortcut, the
+command reopens the tabs closed in Arc itself as well as by arc, most recent first, and restores them
+in the window they were closed in. Nothing happens once there is no closed tab left.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Count < 1 {
+				return errors.New("count must be at least 1")
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc" to activate
+			delay 0.2
+			repeat %d times
+				tell application "System Events" to keystroke "t" using {command down, shift down}
+				delay 0.2
+			end repeat`, flags.Count)); err != nil {
+				return uiScriptingError(err)
+			}
+
+			infof(cmd, "sent %d reopen shortcuts\n", flags.Count)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&flags.Count, "count", "n", 1, "number of tabs to reopen")
+	return cmd
+}
+
+func NewCmdTabMove() *cobra.Command {
+	var flags struct {
+		ToWindow  int
+		Position  int
+		NewWindow bool
+		Incognito bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "move <tab-id>",
+		Short: "Move a tab to another window or position",
+		Long: `Move a tab to another window or position.
+
+Arc's scripting dictionary does not support moving tabs, so the tab is recreated with the same url at the
+requested location, and the original tab is closed. The page is reloaded, and the tab gets a new id.
+
+With --new-window, the tab is moved to a new window, which becomes the front window. The new id of the
+tab is printed in both cases.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeTabIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("to-window") && !cmd.Flags().Changed("position") && !flags.NewWindow {
+				return fmt.Errorf("either --to-window, --position or --new-window must be set")
+			}
+
+			if flags.Incognito && !flags.NewWindow {
+				return fmt.Errorf("--incognito can only be used with --new-window")
+			}
+
+			tabs, err := listTabs()
+			if err != nil {
+				return err
+			}
+
+			tab, err := findTab(tabs, args[0])
+			if err != nil {
+				return err
+			}
+
+			if flags.NewWindow {
+				makeWindow := "make new window"
+				if flags.Incognito {
+					makeWindow = "make new window with properties {incognito:true}"
+				}
+
+				// the new window becomes the front one, shifting the ids of the other windows,
+				// so the original tab is looked up by id in every window
+				output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+					%s
+					tell front window
+						set newTab to make new tab with properties {URL:"%s"}
+						set newTabID to id of newTab
+					end tell
+					repeat with _window in windows
+						tell _window
+							if (count of (tabs whose id is "%s")) > 0 then close (first tab whose id is "%[3]s")
+						end tell
+					end repeat
+					return newTabID
+				end tell`, makeWindow, escapeApplescript(tab.URL), escapeApplescript(tab.ID)))
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(output)))
+				return nil
+			}
+
+			windowID := tab.WindowID
+			if cmd.Flags().Changed("to-window") {
+				windowID = flags.ToWindow
+			}
+
+			location := ""
+			if cmd.Flags().Changed("position") {
+				if flags.Position < 1 {
+					return fmt.Errorf("position must be greater than 0")
+				}
+				location = fmt.Sprintf(" at before tab %d", flags.Position)
+			}
+
+			output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell window %d
+					set newTab to make new tab%s with properties {URL:"%s"}
+					set newTabID to id of newTab
+				end tell
+				tell window %d
+					close (first tab whose id is "%s")
+				end tell
+				return newTabID
+			end tell`, windowID, location, escapeApplescript(tab.URL), tab.WindowID, escapeApplescript(tab.ID)))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(output)))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.ToWindow, "to-window", 0, "window to move the tab to")
+	cmd.RegisterFlagCompletionFunc("to-window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.Position, "position", 0, "1-based position of the tab in the target window")
+	cmd.Flags().BoolVar(&flags.NewWindow, "new-window", false, "move the tab to a new window")
+	cmd.Flags().BoolVar(&flags.Incognito, "incognito", false, "make the new window incognito, with --new-window")
+	cmd.MarkFlagsMutuallyExclusive("new-window", "to-window")
+	cmd.MarkFlagsMutuallyExclusive("new-window", "position")
+	return cmd
+}
+
+func NewCmdTabDuplicate() *cobra.Command {
+	var flags struct {
+		ID        int
+		NewWindow bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "duplicate",
+		Short: "Open a copy of a tab",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := "active tab"
+			if cmd.Flags().Changed("id") {
+				source = fmt.Sprintf("tab %d", flags.ID)
+			}
+
+			makeWindow := ""
+			if flags.NewWindow {
+				makeWindow = "make new window"
+			}
+
+			output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				set tabURL to URL of %s of front window
+				%s
+				tell front window
+					set newTab to make new tab with properties {URL:tabURL}
+				end tell
+				%s
+				return id of newTab
+			end tell`, source, makeWindow, activateStatement()))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(output)))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab to duplicate (defaults to the active tab)")
+	cmd.Flags().BoolVar(&flags.NewWindow, "new-window", false, "open the copy in a new window")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	return cmd
+}
+
+//go:embed applescript/pin-tab.applescript
+var pinTabScript string
+
+func NewCmdTabPin() *cobra.Command {
+	return newCmdTabSetLocation("pin", "Pin a tab", "pinned")
+}
+
+func NewCmdTabUnpin() *cobra.Command {
+	return newCmdTabSetLocation("unpin", "Unpin a tab", "unpinned")
+}
+
+func newCmdTabSetLocation(use string, short string, location string) *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: short + `.
+
+Arc does not allow changing the pinned state of a tab through applescript, so the tab is selected and
+the Cmd+D shortcut is sent through System Events. This uses ui scripting (accessibility permission, see
+arc doctor).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := "active"
+			if cmd.Flags().Changed("id") {
+				target = strconv.Itoa(flags.ID)
+			}
+
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			if _, err := runApplescript(pinTabScript, strconv.Itoa(windowID), target, location); err != nil {
+				return uiScriptingError(err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	return cmd
+}
+
+//go:embed applescript/bookmark-tab.applescript
+var bookmarkTabScript string
+
+func NewCmdTabBookmark() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "bookmark",
+		Short: "Add a tab to the favorites",
+		Long: `Add a tab to the favorites.
+
+Arc does not allow changing the location of a tab through applescript, so the tab is selected and the
+Add to Favorites item of the Tabs menu is clicked through System Events. This uses ui scripting
+(accessibility permission, see arc doctor). The tab is checked to be a favorite afterwards.
+
+Arc has no reading list: favorites are the only bookmarks it keeps.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := "active"
+			if cmd.Flags().Changed("id") {
+				target = strconv.Itoa(flags.ID)
+			}
+
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			output, err := runApplescript(bookmarkTabScript, strconv.Itoa(windowID), target)
+			if err != nil {
+				return uiScriptingError(err)
+			}
+
+			if strings.TrimSpace(string(output)) == "unchanged" {
+				infof(cmd, "the tab is already a favorite\n")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	return cmd
+}
+
+//go:embed applescript/archive-tab.applescript
+var archiveTabScript string
+
+func NewCmdTabArchive() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+		All    bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Archive a tab",
+		Long: `Archive a tab, like Arc does with the tabs left unused.
+
+Unlike tab close, which removes the tab through applescript, archiving keeps the tab in Arc's archive
+of the space, from which it can be restored later. With --all, every unpinned tab of the window is
+archived, leaving the pinned tabs and the favorites open.
+
+Arc does not allow archiving tabs through applescript, so each tab is selected and the Archive Tab item
+of the Tabs menu is clicked through System Events. This uses ui scripting (accessibility permission,
+see arc doctor).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			var tabIDs []string
+			switch {
+			case flags.All:
+				tabs, err := listTabs()
+				if err != nil {
+					return err
+				}
+
+				for _, tab := range tabs {
+					if tab.WindowID == windowID && tab.Location == "unpinned" {
+						tabIDs = append(tabIDs, tab.ID)
+					}
+				}
+			case cmd.Flags().Changed("id"):
+				tabs, err := listTabs()
+				if err != nil {
+					return err
+				}
+
+				for _, tab := range tabs {
+					if tab.WindowID == windowID && tab.Index == flags.ID {
+						tabIDs = append(tabIDs, tab.ID)
+					}
+				}
+
+				if len(tabIDs) == 0 {
+					return notFoundf("no tab found with id %d in window %d", flags.ID, windowID)
+				}
+			default:
+				tab, err := getActiveTab(flags.Window)
+				if err != nil {
+					return err
+				}
+				tabIDs = append(tabIDs, tab.ID)
+			}
+
+			if len(tabIDs) == 0 {
+				infof(cmd, "no tab to archive\n")
+				return nil
+			}
+
+			output, err := runApplescript(archiveTabScript, append([]string{strconv.Itoa(windowID)}, tabIDs...)...)
+			if err != nil {
+				return uiScriptingError(err)
+			}
+
+			infof(cmd, "archived %s tabs\n", strings.TrimSpace(string(output)))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tabs (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().BoolVar(&flags.All, "all", false, "archive every unpinned tab of the window")
+	cmd.MarkFlagsMutuallyExclusive("id", "all")
+	return cmd
+}
+
+//go:embed applescript/rename-tab.applescript
+var renameTabScript string
+
+func NewCmdTabSetTitle() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+	}
+
+	cmd := &cobra.Command{
+		Use:     "set-title <title>",
+		Aliases: []string{"rename"},
+		Short:   "Rename a tab",
+		Long: `Rename a tab, replacing the title of its page in the sidebar.
+
+Arc does not allow renaming tabs through applescript, so the tab is selected and renamed from the Tabs
+menu through System Events. This uses ui scripting (accessibility permission, see arc doctor). The
+title of the tab is read again afterwards to check that it was renamed; Arc may only keep the new title
+of pinned tabs and favorites.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := "active"
+			if cmd.Flags().Changed("id") {
+				target = strconv.Itoa(flags.ID)
+			}
+
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			output, err := runApplescript(renameTabScript, strconv.Itoa(windowID), target, args[0])
+			if err != nil {
+				return uiScriptingError(err)
+			}
+
+			// the window of the tab was brought to the front
+			title, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell front window
+					return title of (first tab whose id is "%s")
+				end tell
+			end tell`, escapeApplescript(strings.TrimSpace(string(output)))))
+			if err != nil {
+				return err
+			}
+
+			if title := strings.TrimSpace(string(title)); title != args[0] {
+				return fmt.Errorf("the tab was not renamed: its title is %q instead of %q", title, args[0])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	return cmd
+}
+
+//go:embed applescript/print-tab.applescript
+var printTabScript string
+
+// printTimeout is how long tab print waits for the pdf to be saved.
+const printTimeout = 10 * time.Second
+
+func NewCmdTabPrint() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+		PDF    string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print a tab",
+		Long: `Print a tab, by opening the print dialog of Arc.
+
+With --pdf, the tab is saved as a pdf file instead, whose path is printed once it is written. The
+system print dialog is opened with Cmd+Option+P, Save as PDF is picked from its PDF menu, and the path
+is typed in the save dialog.
+
+Arc cannot print through applescript, so the tab is selected and the keyboard shortcuts and clicks are
+sent through System Events. This uses ui scripting (accessibility permission, see arc doctor). Arc must
+stay in the front until the dialogs are closed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := "active"
+			if cmd.Flags().Changed("id") {
+				target = strconv.Itoa(flags.ID)
+			}
+
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			if flags.PDF == "" {
+				if _, err := runApplescript(printTabScript, strconv.Itoa(windowID), target); err != nil {
+					return uiScriptingError(err)
+				}
+				return nil
+			}
+
+			path, err := filepath.Abs(flags.PDF)
+			if err != nil {
+				return err
+			}
+
+			// the save dialog adds the extension when it is missing
+			if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+				path += ".pdf"
+			}
+
+			// the save dialog would ask before replacing the file, which is not scripted
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists", path)
+			}
+
+			if _, err := runApplescript(printTabScript, strconv.Itoa(windowID), target, filepath.Dir(path), filepath.Base(path)); err != nil {
+				return uiScriptingError(err)
+			}
+
+			for start := time.Now(); time.Since(start) < printTimeout; time.Sleep(250 * time.Millisecond) {
+				if _, err := os.Stat(path); err == nil {
+					fmt.Fprintln(cmd.OutOrStdout(), path)
+					return nil
+				}
+			}
+
+			return fmt.Errorf("%s was not saved after %s", path, printTimeout)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().StringVar(&flags.PDF, "pdf", "", "save the tab as a pdf file at this path, instead of opening the print dialog")
+	cmd.MarkFlagFilename("pdf", "pdf")
+	return cmd
+}
+
+//go:embed applescript/move-tab-to-space.applescript
+var moveTabToSpaceScript string
+
+func NewCmdTabMoveToSpace() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "move-to-space <name-or-index>",
+		Short: "Move a tab to another space",
+		Long: `Move a tab to another space of its window.
+
+The space is looked up by its 1-based index, or by a substring of its title.
+
+Arc does not allow moving tabs between spaces through applescript, so the tab is selected and the space
+is picked from the Tabs > Move Tab to Space menu through System Events. This uses ui scripting
+(accessibility permission, see arc doctor). Unlike tab move, the tab keeps its id and is not
+reloaded.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			space, err := findSpace(args[0])
+			if err != nil {
+				return err
+			}
+
+			target := "active"
+			if cmd.Flags().Changed("id") {
+				target = strconv.Itoa(flags.ID)
+			}
+
+			windowID := flags.Window
+			if windowID == 0 {
+				windowID = 1
+			}
+
+			if _, err := runApplescript(moveTabToSpaceScript, strconv.Itoa(windowID), target, space.Title); err != nil {
+				return uiScriptingError(err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	return cmd
+}
+
+func NewCmdTabActivateAudio() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "activate-audio",
+		Short: "Select the tab playing sound",
+		Long: `Select the tab playing sound, and bring its window to the front.
+
+Arc does not expose whether a tab is playing sound, so the audio and video elements of every tab are
+inspected with javascript, which Arc only runs when "Allow JavaScript from Apple Events" is enabled in
+View > Developer. When several tabs are playing sound the first one is selected, and the others are
+reported.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tabs, err := listTabs()
+			if err != nil {
+				return err
+			}
+
+			var audibleTabs []Tab
+			for _, tab := range tabs {
+				if tabIsAudible(tab) {
+					audibleTabs = append(audibleTabs, tab)
+				}
+			}
+
+			if len(audibleTabs) == 0 {
+				return notFoundf("no tab is playing sound")
+			}
+
+			tab := audibleTabs[0]
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell window %d
+					tell tab %d to select
+				end tell
+				set index of window %d to 1
+				%s
+			end tell`, tab.WindowID, tab.Index, tab.WindowID, activateStatement())); err != nil {
+				return err
+			}
+
+			for _, other := range audibleTabs[1:] {
+				infof(cmd, "also playing sound: window %d, tab %d: %s\n", other.WindowID, other.Index, other.Title)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func NewCmdTabMute() *cobra.Command {
+	return newCmdTabSetMuted("mute", "Mute a tab", true)
+}
+
+func NewCmdTabUnmute() *cobra.Command {
+	return newCmdTabSetMuted("unmute", "Unmute a tab", false)
+}
+
+func newCmdTabSetMuted(use string, short string, muted bool) *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+		All    bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: short + `.
+
+Arc's scripting dictionary cannot mute tabs, so the audio and video elements of the page are muted
+through javascript instead. This requires "Allow JavaScript from Apple Events" to be enabled in Arc's
+Developer menu. Sound played through the Web Audio API is not affected.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			javascript := fmt.Sprintf(`document.querySelectorAll("audio, video").forEach((media) => { media.muted = %t })`, muted)
+
+			if !flags.All {
+				if _, err := executeJavascript(flags.Window, flags.ID, javascript); err != nil {
+					return err
+				}
+
+				return nil
+			}
+
+			tabs, err := listTabs()
+			if err != nil {
+				return err
+			}
+
+			var count int
+			for _, tab := range tabs {
+				if cmd.Flags().Changed("window") && tab.WindowID != flags.Window {
+					continue
+				}
+
+				// when muting, only touch the tabs currently playing sound
+				if muted && !tabIsAudible(tab) {
+					continue
+				}
+
+				if _, err := executeJavascript(tab.WindowID, tab.Index, javascript); err != nil {
+					continue
+				}
+				count++
+			}
+
+			infof(cmd, "%sd %d tabs\n", use, count)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().BoolVar(&flags.All, "all", false, "apply to every tab, in every window unless --window is set (only tabs playing sound are muted)")
+	cmd.MarkFlagsMutuallyExclusive("id", "all")
+	return cmd
+}
+
+var urlSchemeRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// normalizeURL prepends https:// to bare domains like example.com.
+func normalizeURL(url string) string {
+	if strings.Contains(url, "://") {
+		return url
+	}
+
+	// schemes without an authority, like about:blank or mailto:, but not host:port
+	if scheme := urlSchemeRegexp.FindString(url); scheme != "" {
+		if _, err := strconv.Atoi(strings.SplitN(url[len(scheme):], "/", 2)[0]); err != nil {
+			return url
+		}
+	}
+
+	return "https://" + url
+}
+
+func NewCmdTabGoto() *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+	}
+
+	cmd := &cobra.Command{
+		Use:   "goto <url>",
+		Short: "Navigate a tab to a url",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell %s
+					set URL of %s to "%s"
+				end tell
+			end tell`, windowSpecifier(flags.Window), tabSpecifier(flags.ID), escapeApplescript(normalizeURL(args[0])))); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	return cmd
+}
+
+func NewCmdTabBack() *cobra.Command {
+	return newCmdTabHistory("back", "Go back in the history of a tab", -1)
+}
+
+func NewCmdTabForward() *cobra.Command {
+	return newCmdTabHistory("forward", "Go forward in the history of a tab", 1)
+}
+
+func newCmdTabHistory(use string, short string, direction int) *cobra.Command {
+	var flags struct {
+		Window int
+		ID     int
+		Count  int
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: short + `.
+
+Arc's scripting dictionary has no navigation commands, so history.go() is called through javascript.
+This requires "Allow JavaScript from Apple Events" to be enabled in Arc's Developer menu.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Count < 1 {
+				return fmt.Errorf("--count must be positive")
+			}
+
+			if _, err := executeJavascript(flags.Window, flags.ID, fmt.Sprintf("history.go(%d)", direction*flags.Count)); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().IntVarP(&flags.Count, "count", "n", 1, "number of steps")
+	return cmd
+}
+
+var errWaitTimeout = errors.New("timed out waiting for the tab to load")
+
+// waitForTab polls a tab until it is done loading, or until the timeout elapses.
+func waitForTab(window int, tab int, timeout time.Duration, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		// give the navigation a chance to start before the first check
+		time.Sleep(interval)
+
+		output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+			tell %s
+				return loading of %s
+			end tell
+		end tell`, windowSpecifier(window), tabSpecifier(tab)))
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(string(output)) == "false" {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s", errWaitTimeout, timeout)
+		}
+	}
+}
+
+// waitFlags holds the flags of the commands waiting for the tab they open to be done loading.
+type waitFlags struct {
+	Wait          bool
+	Timeout       time.Duration
+	FailOnTimeout bool
+}
+
+func addWaitFlags(cmd *cobra.Command, flags *waitFlags) {
+	cmd.Flags().BoolVar(&flags.Wait, "wait", false, "wait until the opened tab is done loading")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 30*time.Second, "maximum time to wait for, with --wait")
+	cmd.Flags().BoolVar(&flags.FailOnTimeout, "fail-on-timeout", false, "exit with an error when --timeout elapses, instead of printing a warning")
+}
+
+// waitForTab waits for a tab to be done loading if --wait was set.
+func (f waitFlags) waitForTab(cmd *cobra.Command, window int, tab int) error {
+	if !f.Wait {
+		return nil
+	}
+
+	err := waitForTab(window, tab, f.Timeout, 250*time.Millisecond)
+	if errors.Is(err, errWaitTimeout) && !f.FailOnTimeout {
+		cmd.PrintErrf("warning: %s\n", err)
+		return nil
+	}
+
+	return err
+}
+
+func NewCmdTabWait() *cobra.Command {
+	var flags struct {
+		Window   int
+		ID       int
+		Timeout  time.Duration
+		Interval time.Duration
+	}
+
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait until a tab is done loading",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return waitForTab(flags.Window, flags.ID, flags.Timeout, flags.Interval)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 30*time.Second, "maximum time to wait for")
+	cmd.Flags().DurationVar(&flags.Interval, "interval", 250*time.Millisecond, "time between two checks")
+	return cmd
+}
+
+var errURLTimeout = errors.New("timed out waiting for a matching url")
+
+// urlMatcher returns a function reporting whether a url contains pattern, or matches it as a regular
+// expression when regex is set.
+func urlMatcher(pattern string, regex bool) (func(string) bool, error) {
+	if !regex {
+		return func(url string) bool {
+			return strings.Contains(url, pattern)
+		}, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+
+	return re.MatchString, nil
+}
+
+// waitForURL polls the url of a tab until it matches, and returns it, or until the timeout elapses.
+func waitForURL(window int, tab int, match func(string) bool, timeout time.Duration, interval time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+			tell %s
+				return URL of %s
+			end tell
+		end tell`, windowSpecifier(window), tabSpecifier(tab)))
+		if err != nil {
+			return "", err
+		}
+
+		if url := strings.TrimSpace(string(output)); match(url) {
+			return url, nil
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%w after %s", errURLTimeout, timeout)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func NewCmdTabWaitForURL() *cobra.Command {
+	var flags struct {
+		Window   int
+		ID       int
+		Regex    bool
+		Timeout  time.Duration
+		Interval time.Duration
+	}
+
+	cmd := &cobra.Command{
+		Use:   "wait-for-url <pattern>",
+		Short: "Wait until the url of a tab matches a pattern",
+		Long: `Wait until the url of a tab contains a pattern, or matches it as a Go regular expression with
+--regex, and print the url.
+
+The tab is followed through redirections and navigations, which makes it possible to capture the
+url a login flow lands on.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			match, err := urlMatcher(args[0], flags.Regex)
+			if err != nil {
+				return err
+			}
+
+			url, err := waitForURL(flags.Window, flags.ID, match, flags.Timeout, flags.Interval)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), url)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().IntVar(&flags.ID, "id", 0, "id of the tab (defaults to the active tab)")
+	cmd.RegisterFlagCompletionFunc("id", completeTabIndexes)
+	cmd.Flags().BoolVar(&flags.Regex, "regex", false, "match the url against a regular expression")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 2*time.Minute, "maximum time to wait for")
+	cmd.Flags().DurationVar(&flags.Interval, "interval", 250*time.Millisecond, "time between two checks")
+	return cmd
+}
+
+func NewCmdTabCaptureURLOnRedirect() *cobra.Command {
+	var flags struct {
+		Window       int
+		Pattern      string
+		Regex        bool
+		CloseOnMatch bool
+		Timeout      time.Duration
+		Interval     time.Duration
+	}
+
+	cmd := &cobra.Command{
+		Use:   "capture-url-on-redirect",
+		Short: "Print the first url of the active tab matching a pattern",
+		Long: `Watch the active tab of a window and print the first url it shows that matches --pattern, such as
+the callback url an OAuth flow redirects to, for a script to parse the code or token out of it.
+
+The active tab is read again on each check, so the url is captured even when the flow opens in a new
+tab. The callback page does not need to load: a tab left on an error page still reports its url.
+With --close-on-match, the tab showing the url is closed once it is captured.`,
+		Example: `  # extract the code of an OAuth callback
+  code=$(arc tab capture-url-on-redirect --pattern 'localhost:8080/callback?code=' | sed 's/.*code=\([^&]*\).*/\1/')`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			match, err := urlMatcher(flags.Pattern, flags.Regex)
+			if err != nil {
+				return err
+			}
+
+			url, err := waitForURL(flags.Window, 0, match, flags.Timeout, flags.Interval)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), url)
+
+			if flags.CloseOnMatch {
+				// the active tab may have changed since the url was read, so the tab is looked up by url
+				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+					tell %s
+						close (first tab whose URL is "%s")
+					end tell
+				end tell`, windowSpecifier(flags.Window), escapeApplescript(url))); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Window, "window", 0, "window of the tab (defaults to the front window)")
+	cmd.RegisterFlagCompletionFunc("window", completeWindowIDs)
+	cmd.Flags().StringVar(&flags.Pattern, "pattern", "", "substring of the url to capture")
+	cmd.MarkFlagRequired("pattern")
+	cmd.Flags().BoolVar(&flags.Regex, "regex", false, "match the url against --pattern as a regular expression")
+	cmd.Flags().BoolVar(&flags.CloseOnMatch, "close-on-match", false, "close the tab once its url is captured")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 2*time.Minute, "maximum time to wait for")
+	cmd.Flags().DurationVar(&flags.Interval, "interval", 250*time.Millisecond, "time between two checks")
+	return cmd
+}
+
+func NewCmdTabExecute() *cobra.Command {
+	var flags struct {
+		Eval string
+		File string
+	}
+
+	cmd := &cobra.Command{
+		Use:               "exec [tab-id]",
+		Short:             "Execute javascript in the active tab",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeTabIndexes),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var tabID int
+			if len(args) > 0 {
+				id, err := strconv.Atoi(args[0])
+				if err != nil {
+					return err
+				}
+				tabID = id
+			}
+
+			javascript, err := readJavascript(cmd.InOrStdin(), flags.Eval, flags.File)
+			if err != nil {
+				return err
+			}
+
+			output, err := executeJavascript(0, tabID, javascript)
+			if err != nil {
+				return err
+			}
+
+			if _, err := cmd.OutOrStdout().Write(output); err != nil {
+				return err
+			}
+
+			return nil
+		},
 	}
 
 	cmd.Flags().StringVarP(&flags.Eval, "eval", "e", "", "javascript to evaluate")
+	cmd.Flags().StringVarP(&flags.File, "file", "f", "", "file containing the javascript to evaluate")
+	cmd.MarkFlagsMutuallyExclusive("eval", "file")
 	return cmd
 }
 
-func escapeJavascript(javascript string) string {
-	javascript = strings.ReplaceAll(javascript, `\`, `\\'`)
-	javascript = strings.ReplaceAll(javascript, `"`, `\"`)
-	return javascript
+// readJavascript returns the javascript given inline, read from a file, or read from stdin, in that order.
+func readJavascript(stdin io.Reader, inline string, file string) (string, error) {
+	if inline != "" {
+		return inline, nil
+	}
+
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+
+		return string(content), nil
+	}
+
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", err
+		}
+
+		if len(content) > 0 {
+			return string(content), nil
+		}
+	}
+
+	return "", fmt.Errorf("no javascript provided")
 }
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestExpandURLTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   []string
+		params   map[string]string
+		want     string
+	}{
+		{
+			name:     "query",
+			template: "https://duckduckgo.com/?q={}",
+			values:   []string{"go & rust+c"},
+			want:     "https://duckduckgo.com/?q=go+%26+rust%2Bc",
+		},
+		{
+			name:     "path",
+			template: "https://github.com/{}/issues",
+			values:   []string{"a b"},
+			want:     "https://github.com/a%20b/issues",
+		},
+		{
+			name:     "path and query",
+			template: "https://github.com/{repo}/issues?q={}",
+			values:   []string{"is:open a&b"},
+			params:   map[string]string{"repo": "cli"},
+			want:     "https://github.com/cli/issues?q=is%3Aopen+a%26b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandURLTemplate(tt.template, tt.values, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandURLTemplateErrors(t *testing.T) {
+	if _, err := expandURLTemplate("https://example.com/?q={}", nil, nil); err == nil {
+		t.Error("expected an error for a missing value")
+	}
+	if _, err := expandURLTemplate("https://example.com/{name}", nil, nil); err == nil {
+		t.Error("expected an error for a missing param")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdTheme() *cobra.Command {
+	var flags struct {
+		Light            bool
+		Dark             bool
+		System           bool
+		SystemAppearance bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "theme",
+		Short: "Print or switch the appearance of Arc",
+		Long: `Print or switch the appearance of Arc, either light or dark.
+
+Arc has no appearance setting of its own to script: its windows follow the appearance of macOS, and the
+colors of a space are chosen from its theme in Arc's sidebar. --light and --dark therefore switch the
+appearance of macOS through System Events, which affects every application following it, so they must
+be confirmed with --system-appearance.
+
+Controlling System Events requires the automation permission to be granted to your terminal in System
+Settings > Privacy & Security > Automation. The resulting mode is printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (flags.Light || flags.Dark) && !flags.SystemAppearance {
+				return errors.New("--light and --dark switch the appearance of macOS for every application, add --system-appearance to confirm")
+			}
+
+			setDarkMode := ""
+			if flags.Light {
+				setDarkMode = "set dark mode to false"
+			} else if flags.Dark {
+				setDarkMode = "set dark mode to true"
+			}
+
+			// the appearance of macOS can be read and changed without Arc, so runApplescript is bypassed
+			output, err := execApplescript(fmt.Sprintf(`tell application "System Events"
+	tell appearance preferences
+		%s
+		return dark mode
+	end tell
+end tell`, setDarkMode))
+			if err != nil {
+				return err
+			}
+
+			mode := "light"
+			if strings.TrimSpace(string(output)) == "true" {
+				mode = "dark"
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), mode)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.Light, "light", false, "switch to the light appearance")
+	cmd.Flags().BoolVar(&flags.Dark, "dark", false, "switch to the dark appearance")
+	cmd.Flags().BoolVar(&flags.SystemAppearance, "system-appearance", false, "allow --light and --dark to switch the appearance of macOS")
+	cmd.Flags().BoolVar(&flags.System, "system", false, "follow the appearance of macOS, leaving it unchanged")
+	cmd.Flags().MarkDeprecated("system", "Arc always follows the appearance of macOS, run arc theme without flags instead")
+	cmd.MarkFlagsMutuallyExclusive("light", "dark", "system")
+	return cmd
+}
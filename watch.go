@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// watchEvent is printed as a json line each time the active tab changes.
+type watchEvent struct {
+	Time     time.Time `json:"time"`
+	WindowID int       `json:"windowId"`
+	Index    int       `json:"index"`
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url"`
+}
+
+func NewCmdWatch() *cobra.Command {
+	var flags struct {
+		Interval time.Duration
+		OnChange string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print the active tab each time it changes",
+		Long: `Print the active tab of the front window as a json line each time its url or title changes,
+until interrupted.
+
+With --on-change, the command is also run through sh on each change, with the tab described by the
+ARC_URL, ARC_TITLE, ARC_TAB_ID, ARC_TAB_INDEX and ARC_WINDOW_ID environment variables.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Interval <= 0 {
+				return errors.New("interval must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetEscapeHTML(false)
+
+			var last Tab
+			ticker := time.NewTicker(flags.Interval)
+			defer ticker.Stop()
+
+			for {
+				tab, err := getActiveTab(0)
+				if err != nil {
+					return err
+				}
+
+				if tab.URL != last.URL || tab.Title != last.Title {
+					last = tab
+
+					if err := encoder.Encode(watchEvent{
+						Time:     time.Now(),
+						WindowID: tab.WindowID,
+						Index:    tab.Index,
+						ID:       tab.ID,
+						Title:    tab.Title,
+						URL:      tab.URL,
+					}); err != nil {
+						return err
+					}
+
+					if flags.OnChange != "" {
+						if err := runOnChange(ctx, cmd, flags.OnChange, tab); err != nil {
+							cmd.PrintErrf("warning: %s: %s\n", flags.OnChange, err)
+						}
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&flags.Interval, "interval", 2*time.Second, "interval between two checks of the active tab")
+	cmd.Flags().StringVar(&flags.OnChange, "on-change", "", "shell command to run each time the active tab changes")
+	return cmd
+}
+
+// runOnChange runs the --on-change command of watch, describing the tab through environment variables.
+func runOnChange(ctx context.Context, cmd *cobra.Command, command string, tab Tab) error {
+	onChange := exec.CommandContext(ctx, "sh", "-c", command)
+	onChange.Env = append(os.Environ(),
+		"ARC_URL="+tab.URL,
+		"ARC_TITLE="+tab.Title,
+		"ARC_TAB_ID="+tab.ID,
+		"ARC_TAB_INDEX="+strconv.Itoa(tab.Index),
+		"ARC_WINDOW_ID="+strconv.Itoa(tab.WindowID),
+	)
+	onChange.Stdout = cmd.OutOrStdout()
+	onChange.Stderr = cmd.ErrOrStderr()
+	return onChange.Run()
+}
@@ -2,22 +2,34 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	_ "embed"
 
-	"github.com/cli/go-gh/v2/pkg/tableprinter"
-	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 type Window struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
+	ID        int    `json:"id" yaml:"id"`
+	ArcID     string `json:"arcId" yaml:"arcId"`
+	Title     string `json:"title" yaml:"title"`
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	TabCount  int    `json:"tabCount" yaml:"tabCount"`
+	Incognito bool   `json:"incognito" yaml:"incognito"`
+	Tabs      []Tab  `json:"tabs,omitempty" yaml:"tabs,omitempty"`
+}
+
+func (w Window) porcelainRow() []string {
+	return []string{strconv.Itoa(w.ID), strconv.Itoa(w.TabCount), strconv.FormatBool(w.Incognito), w.Title, w.Name}
 }
 
 func NewCmdWindow() *cobra.Command {
@@ -29,72 +41,198 @@ func NewCmdWindow() *cobra.Command {
 	cmd.AddCommand(NewCmdWindowCreate())
 	cmd.AddCommand(NewCmdWindowClose())
 	cmd.AddCommand(NewCmdWindowList())
+	cmd.AddCommand(NewCmdWindowFocus())
+	cmd.AddCommand(NewCmdWindowMove())
+	cmd.AddCommand(NewCmdWindowResize())
+	cmd.AddCommand(NewCmdWindowMaximize())
+	cmd.AddCommand(NewCmdWindowMinimize())
+	cmd.AddCommand(NewCmdWindowFullscreen())
 
 	return cmd
 }
 
+// windowSpecifier returns the applescript reference of the window with the
+// given id, or of the front window when id is 0.
+func windowSpecifier(id int) string {
+	if id == 0 {
+		return "front window"
+	}
+
+	return fmt.Sprintf("window %d", id)
+}
+
 func NewCmdWindowCreate() *cobra.Command {
 	var flags struct {
 		Incognito bool
-		Focus     string
+		Profile   string
+		Space     string
+		Name      string
+		Keep      bool
+		focusFlags
+		waitFlags
 	}
 
 	cmd := &cobra.Command{
 		Use:     "create [url]",
 		Short:   "Create a new window",
 		Aliases: []string{"new"},
-		Args:    cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if flags.Focus != "" {
-				return windowCreateWithFocus(flags.Incognito, flags.Focus)
-			}
-
-			var applescript string
-			if flags.Incognito {
-				applescript = `tell application "Arc"
-					make new window with properties {incognito:true}
-					activate
-				end tell`
-			} else {
-				applescript = `tell application "Arc"
-					make new window
-				end tell`
-			}
+		Long: `Create a new window.
 
-			if _, err := runApplescript(applescript); err != nil {
-				return err
-			}
+Arc's applescript dictionary cannot choose the profile of a new window, so with --profile Arc is
+launched through open(1) with the --profile-directory switch instead. The window then opens under
+that profile, but the space it shows is the last one used in the profile.
 
-			if len(args) > 0 {
-				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
-					tell front window
-						make new tab with properties {URL:"%s"}
-					end tell
-				end tell`, args[0])); err != nil {
+Arc has no way to rename a window, its title is the one of its active tab. The name given by --name is
+kept by arc instead, shown by window list for as long as the window stays open.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Profile != "" {
+				if err := windowCreateWithProfile(flags.Profile, args); err != nil {
 					return err
 				}
+
+				if len(args) > 0 {
+					return flags.waitForTab(cmd, 0, 0)
+				}
+
+				return nil
 			}
 
-			if _, err := runApplescript(`tell application "Arc" to activate`); err != nil {
+			if err := windowCreate(cmd, flags.Incognito, flags.focusFlags, flags.Space, flags.Keep, flags.waitFlags, args); err != nil {
 				return err
 			}
 
+			if flags.Name != "" {
+				return nameWindow(1, flags.Name)
+			}
+
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&flags.Incognito, "incognito", false, "open in incognito mode")
-	cmd.Flags().StringVar(&flags.Focus, "focus", "", "focus the tab whose title contains this string")
+	addFocusFlags(cmd, &flags.focusFlags)
+	addWaitFlags(cmd, &flags.waitFlags)
+	cmd.Flags().StringVar(&flags.Profile, "profile", "", "open the window under this profile, by name or directory")
+	cmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	cmd.MarkFlagsMutuallyExclusive("wait", "focus")
+	cmd.MarkFlagsMutuallyExclusive("profile", "focus")
+	cmd.MarkFlagsMutuallyExclusive("profile", "incognito")
+	cmd.Flags().StringVar(&flags.Space, "space", "", "switch the window to this space, by name or index")
+	cmd.MarkFlagsMutuallyExclusive("space", "focus")
+	cmd.MarkFlagsMutuallyExclusive("space", "profile")
+	cmd.MarkFlagsMutuallyExclusive("space", "incognito")
+	cmd.Flags().StringVar(&flags.Name, "name", "", "name the window, as shown by window list")
+	cmd.MarkFlagsMutuallyExclusive("name", "profile")
+	addKeepExistingFlag(cmd, &flags.Keep)
+	cmd.MarkFlagsMutuallyExclusive("keep-existing", "profile")
 
 	return cmd
 }
 
-func windowCreateWithFocus(incognito bool, search string) error {
-	// Check if Arc is already running before we launch it
-	wasRunning := true
-	out, err := runApplescript(`application "Arc" is running`)
-	if err == nil && strings.TrimSpace(string(out)) == "false" {
-		wasRunning = false
+// windowCreate creates a window, switched to a space if spaceName is set, with a tab opened on the
+// url given as argument if any, or focuses a tab of the new window when --focus is set. The windows
+// restored by Arc are closed if it was launched to create the window, unless keepExisting is set.
+func windowCreate(cmd *cobra.Command, incognito bool, focus focusFlags, spaceName string, keepExisting bool, wait waitFlags, args []string) error {
+	if focus.Focus != "" {
+		return windowCreateWithFocus(incognito, focus, keepExisting)
+	}
+
+	// look up the space first, so that no window is created when it does not exist
+	var space Space
+	if spaceName != "" {
+		var err error
+		if space, err = findSpace(spaceName); err != nil {
+			return err
+		}
+	}
+
+	var applescript string
+	if incognito {
+		applescript = `tell application "Arc"
+			make new window with properties {incognito:true}
+		end tell`
+	} else {
+		applescript = `tell application "Arc"
+			make new window
+		end tell`
+	}
+
+	if _, err := runApplescript(applescript); err != nil {
+		return err
+	}
+
+	if !keepExisting {
+		if err := closeStartupWindows(); err != nil {
+			return err
+		}
+	}
+
+	if spaceName != "" {
+		if err := switchSpace(space); err != nil {
+			return err
+		}
+	}
+
+	if len(args) > 0 {
+		if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+			tell front window
+				make new tab with properties {URL:"%s"}
+			end tell
+		end tell`, escapeApplescript(args[0]))); err != nil {
+			return err
+		}
+	}
+
+	if err := activateArc(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		return wait.waitForTab(cmd, 0, 0)
+	}
+
+	return nil
+}
+
+// windowCreateWithProfile opens a window under a profile, given by its display name or directory.
+func windowCreateWithProfile(name string, urls []string) error {
+	profile, err := findProfile(name)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{"-na", appName, "--args", "--profile-directory=" + profile.Directory}, urls...)
+	if output, err := exec.Command("open", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open a window under profile %q: %w: %s", profile.Name, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// focusFlags are the flags of the commands creating a window with a tab to focus, tuning how long
+// to wait for the tabs of the window to load.
+type focusFlags struct {
+	Focus      string
+	Retries    int
+	RetryDelay time.Duration
+	LoadDelay  time.Duration
+}
+
+func addFocusFlags(cmd *cobra.Command, flags *focusFlags) {
+	cmd.Flags().StringVar(&flags.Focus, "focus", "", "focus the tab whose title contains this string")
+	cmd.Flags().IntVar(&flags.Retries, "retries", 10, "number of lookups of the tab to focus, with --focus")
+	cmd.Flags().DurationVar(&flags.RetryDelay, "retry-delay", 500*time.Millisecond, "delay between two lookups of the tab to focus, with --focus")
+	cmd.Flags().DurationVar(&flags.LoadDelay, "load-delay", time.Second, "delay before the first lookup of the tab to focus, with --focus")
+}
+
+func windowCreateWithFocus(incognito bool, focus focusFlags, keepExisting bool) error {
+	if focus.Retries < 1 {
+		return errors.New("retries must be at least 1")
+	}
+
+	if focus.LoadDelay < 0 || focus.RetryDelay < 0 {
+		return errors.New("delays cannot be negative")
 	}
 
 	makeWindow := `make new window`
@@ -102,14 +240,10 @@ func windowCreateWithFocus(incognito bool, search string) error {
 		makeWindow = `make new window with properties {incognito:true}`
 	}
 
-	// Escape the search string for AppleScript
-	escaped := strings.ReplaceAll(search, `\`, `\\`)
-	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
-
 	applescript := fmt.Sprintf(`tell application "Arc"
 	%s
-	delay 1
-	set maxRetries to 10
+	delay %.3[4]f
+	set maxRetries to %[5]d
 	repeat with attempt from 1 to maxRetries
 		tell front window
 			set tabIndex to 1
@@ -117,9 +251,9 @@ func windowCreateWithFocus(incognito bool, search string) error {
 				try
 					set tabTitle to title of aTab
 					ignoring case
-						if tabTitle contains "%s" then
+						if tabTitle contains "%[2]s" then
 							tell tab tabIndex to select
-							activate
+							%[3]s
 							return "found"
 						end if
 					end ignoring
@@ -127,43 +261,146 @@ func windowCreateWithFocus(incognito bool, search string) error {
 				set tabIndex to tabIndex + 1
 			end repeat
 		end tell
-		if attempt < maxRetries then delay 0.5
+		if attempt < maxRetries then delay %.3[6]f
 	end repeat
-	activate
+	%[3]s
 	return "not_found"
-end tell`, makeWindow, escaped)
+end tell`, makeWindow, escapeApplescript(focus.Focus), activateStatement(), focus.LoadDelay.Seconds(), focus.Retries, focus.RetryDelay.Seconds())
 
 	output, err := runApplescript(applescript)
 	if err != nil {
 		return err
 	}
 
-	// If Arc was not running, it opens startup windows alongside ours.
-	// Close all windows except the front one (which is the one we just created).
-	if !wasRunning {
-		if _, err := runApplescript(`tell application "Arc"
-	set windowCount to count of windows
-	repeat with i from windowCount to 2 by -1
-		close window i
-	end repeat
-end tell`); err != nil {
+	if !keepExisting {
+		if err := closeStartupWindows(); err != nil {
 			return err
 		}
 	}
 
 	if strings.TrimSpace(string(output)) == "not_found" {
-		return fmt.Errorf("no tab found with title containing %q", search)
+		return notFoundf("no tab found with title containing %q", focus.Focus)
 	}
 
 	return nil
 }
 
+func addKeepExistingFlag(cmd *cobra.Command, keep *bool) {
+	cmd.Flags().BoolVar(keep, "keep-existing", false, "keep the windows restored by Arc when it is launched to open the new window")
+}
+
+// closeStartupWindows closes the windows Arc restores when it is launched by arc, alongside the
+// window created by the command. They are all the windows but the front one, which is the new one.
+// Nothing is closed when Arc was already running.
+func closeStartupWindows() error {
+	if arcWasRunning {
+		return nil
+	}
+
+	_, err := runApplescript(`tell application "Arc"
+	set windowCount to count of windows
+	repeat with i from windowCount to 2 by -1
+		close window i
+	end repeat
+end tell`)
+	return err
+}
+
 //go:embed applescript/list-windows.applescript
 var listWindowsScript string
 
+func listWindows() ([]Window, error) {
+	output, err := runApplescript(listWindowsScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []Window
+	if err := json.Unmarshal(output, &windows); err != nil {
+		return nil, err
+	}
+
+	names, err := readWindowNames()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range windows {
+		if windows[i].ArcID != "" {
+			windows[i].Name = names[windows[i].ArcID]
+		}
+	}
+
+	return windows, nil
+}
+
+// windowNamesPath is the file keeping the names given to windows, by arc id.
+func windowNamesPath() string {
+	return filepath.Join(stateDir(), "window-names.json")
+}
+
+func readWindowNames() (map[string]string, error) {
+	names := make(map[string]string)
+	content, err := os.ReadFile(windowNamesPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return names, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the window names: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &names); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", windowNamesPath(), err)
+	}
+
+	return names, nil
+}
+
+// nameWindow saves the name of a window, forgetting the names of the windows that were closed.
+func nameWindow(windowID int, name string) error {
+	windows, err := listWindows()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	found := false
+	for _, window := range windows {
+		if window.ArcID == "" {
+			continue
+		}
+
+		if window.ID == windowID {
+			names[window.ArcID] = name
+			found = true
+		} else if window.Name != "" {
+			names[window.ArcID] = window.Name
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("cannot name window %d: Arc does not report its id", windowID)
+	}
+
+	content, err := json.MarshalIndent(names, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(windowNamesPath(), content, 0o644)
+}
+
 func NewCmdWindowList() *cobra.Command {
 	flags := struct {
-		Json bool
+		Sort      string
+		Reverse   bool
+		Filter    string
+		Incognito bool
+		Normal    bool
+		outputFlags
 	}{}
 
 	cmd := &cobra.Command{
@@ -171,39 +408,69 @@ func NewCmdWindowList() *cobra.Command {
 		Aliases: []string{"ls"},
 		Short:   "List windows",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output, err := runApplescript(listWindowsScript)
+			if flags.Sort != "id" && flags.Sort != "title" {
+				return fmt.Errorf("invalid sort key %q, must be one of id or title", flags.Sort)
+			}
+
+			windows, err := listWindows()
 			if err != nil {
 				return err
 			}
 
-			var windows []Window
-			if err := json.Unmarshal(output, &windows); err != nil {
-				return err
+			if flags.Filter != "" {
+				var filteredWindows []Window
+				for _, window := range windows {
+					if strings.Contains(strings.ToLower(window.Title), strings.ToLower(flags.Filter)) || strings.Contains(strings.ToLower(window.Name), strings.ToLower(flags.Filter)) {
+						filteredWindows = append(filteredWindows, window)
+					}
+				}
+				windows = filteredWindows
 			}
 
-			if flags.Json {
-				encoder := json.NewEncoder(cmd.OutOrStdout())
-				encoder.SetIndent("", "  ")
-				encoder.SetEscapeHTML(false)
-				return encoder.Encode(windows)
+			if flags.Incognito || flags.Normal {
+				var modeWindows []Window
+				for _, window := range windows {
+					if window.Incognito == flags.Incognito {
+						modeWindows = append(modeWindows, window)
+					}
+				}
+				windows = modeWindows
 			}
 
-			var printer tableprinter.TablePrinter
-			if !isatty.IsTerminal(os.Stdout.Fd()) {
-				printer = tableprinter.New(os.Stdout, false, 0)
-			} else {
-				w, _, err := term.GetSize(int(os.Stdout.Fd()))
-				if err != nil {
-					return err
+			sort.SliceStable(windows, func(i, j int) bool {
+				if flags.Sort == "title" {
+					if compare := strings.Compare(strings.ToLower(windows[i].Title), strings.ToLower(windows[j].Title)); compare != 0 {
+						return (compare < 0) != flags.Reverse
+					}
+
+					// ties are broken by ascending id
+					return windows[i].ID < windows[j].ID
 				}
 
-				printer = tableprinter.New(os.Stdout, true, w)
+				return (windows[i].ID < windows[j].ID) != flags.Reverse
+			})
+
+			if ok, err := printItems(cmd.OutOrStdout(), flags.outputFlags, windows); ok || err != nil {
+				return err
 			}
 
-			printer.AddHeader([]string{"ID", "Title"})
+			printer, err := newOutputTablePrinter(cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			printer.AddHeader([]string{"ID", "Name", "Title", "Tabs", "Mode"})
 			for _, window := range windows {
+				mode := "normal"
+				if window.Incognito {
+					mode = "incognito"
+				}
+
 				printer.AddField(fmt.Sprintf("%d", window.ID))
+				printer.AddField(window.Name)
 				printer.AddField(window.Title)
+				printer.AddField(strconv.Itoa(window.TabCount))
+				printer.AddField(mode)
 				printer.EndRow()
 			}
 
@@ -211,37 +478,376 @@ func NewCmdWindowList() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().BoolVar(&flags.Json, "json", false, "output as json")
+	cmd.Flags().StringVar(&flags.Sort, "sort", "id", "sort the windows by id or title")
+	cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"id", "title"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().BoolVar(&flags.Reverse, "reverse", false, "reverse the sort order")
+	cmd.Flags().StringVar(&flags.Filter, "filter", "", "only show windows whose title or name contains this string")
+	cmd.Flags().BoolVar(&flags.Incognito, "incognito", false, "only show incognito windows")
+	cmd.Flags().BoolVar(&flags.Normal, "normal", false, "only show windows that are not incognito")
+	cmd.MarkFlagsMutuallyExclusive("incognito", "normal")
+	addOutputFlags(cmd, &flags.outputFlags)
 	return cmd
 }
 
+func NewCmdWindowFocus() *cobra.Command {
+	var flags struct {
+		Title string
+	}
+
+	cmd := &cobra.Command{
+		Use:               "focus [id]",
+		Short:             "Bring a window to the front",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeWindowIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && !cmd.Flags().Changed("title") {
+				return fmt.Errorf("either a window id or --title must be provided")
+			}
+
+			windows, err := listWindows()
+			if err != nil {
+				return err
+			}
+
+			window, err := findWindow(windows, args, flags.Title)
+			if err != nil {
+				return err
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				set index of window %d to 1
+				%s
+			end tell`, window.ID, activateStatement())); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.Title, "title", "", "focus the first window whose title contains this string")
+	return cmd
+}
+
+// windowArg returns the window id given as first argument, or 0 for the front window.
+func windowArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	return strconv.Atoi(args[0])
+}
+
+func NewCmdWindowMove() *cobra.Command {
+	var flags struct {
+		X int
+		Y int
+	}
+
+	cmd := &cobra.Command{
+		Use:               "move [id]",
+		Short:             "Move a window, keeping its size",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeWindowIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windowID, err := windowArg(args)
+			if err != nil {
+				return err
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell %s
+					set {x1, y1, x2, y2} to bounds
+					set bounds to {%[2]d, %[3]d, %[2]d + x2 - x1, %[3]d + y2 - y1}
+				end tell
+			end tell`, windowSpecifier(windowID), flags.X, flags.Y)); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.X, "x", 0, "horizontal position of the left edge of the window")
+	cmd.Flags().IntVar(&flags.Y, "y", 0, "vertical position of the top edge of the window")
+	return cmd
+}
+
+func NewCmdWindowResize() *cobra.Command {
+	var flags struct {
+		Width  int
+		Height int
+	}
+
+	cmd := &cobra.Command{
+		Use:               "resize [id]",
+		Short:             "Resize a window, keeping its position",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeWindowIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.Width <= 0 || flags.Height <= 0 {
+				return fmt.Errorf("--width and --height must be positive, got %dx%d", flags.Width, flags.Height)
+			}
+
+			windowID, err := windowArg(args)
+			if err != nil {
+				return err
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+				tell %s
+					set {x1, y1, x2, y2} to bounds
+					set bounds to {x1, y1, x1 + %d, y1 + %d}
+				end tell
+			end tell`, windowSpecifier(windowID), flags.Width, flags.Height)); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.Width, "width", 0, "width of the window")
+	cmd.Flags().IntVar(&flags.Height, "height", 0, "height of the window")
+	cmd.MarkFlagRequired("width")
+	cmd.MarkFlagRequired("height")
+	return cmd
+}
+
+func NewCmdWindowMaximize() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "maximize [id]",
+		Short:             "Resize a window to fill the visible frame of the screen",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeWindowIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windowID, err := windowArg(args)
+			if err != nil {
+				return err
+			}
+
+			// The visible frame excludes the menu bar and the dock. Cocoa uses a bottom-left origin,
+			// while applescript bounds use a top-left one.
+			if _, err := runApplescript(fmt.Sprintf(`use framework "AppKit"
+use scripting additions
+
+set _screen to current application's NSScreen's mainScreen()
+set {{_fx, _fy}, {_fw, _fh}} to _screen's frame()
+set {{_vx, _vy}, {_vw, _vh}} to _screen's visibleFrame()
+set _top to _fh - (_vy + _vh)
+
+tell application "Arc"
+	set bounds of %s to {_vx, _top, _vx + _vw, _top + _vh}
+	%s
+end tell`, windowSpecifier(windowID), activateStatement())); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func NewCmdWindowMinimize() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "minimize [id]",
+		Short:             "Minimize a window to the dock",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeWindowIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windowID, err := windowArg(args)
+			if err != nil {
+				return err
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc" to set miniaturized of %s to true`, windowSpecifier(windowID))); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func NewCmdWindowFullscreen() *cobra.Command {
+	var flags struct {
+		Toggle bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   "fullscreen [id]",
+		Short: "Enter fullscreen mode",
+		Long: `Enter fullscreen mode.
+
+The window is switched to fullscreen through System Events. This uses ui scripting (accessibility
+permission, see arc doctor).`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: onlyFirstArg(completeWindowIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windowID, err := windowArg(args)
+			if err != nil {
+				return err
+			}
+
+			fullscreen := "true"
+			if flags.Toggle {
+				fullscreen = "not _fullscreen"
+			}
+
+			if _, err := runApplescript(fmt.Sprintf(`tell application "Arc"
+	set index of %s to 1
+	activate
+end tell
+delay 0.5
+tell application "System Events" to tell process "Arc"
+	set _window to front window
+	set _fullscreen to value of attribute "AXFullScreen" of _window
+	set value of attribute "AXFullScreen" of _window to %s
+end tell`, windowSpecifier(windowID), fullscreen)); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.Toggle, "toggle", false, "exit fullscreen mode if the window is already fullscreen")
+	return cmd
+}
+
+// findWindow looks up a window either by the id given as first argument, or
+// by a case-insensitive substring of its title.
+func findWindow(windows []Window, args []string, title string) (Window, error) {
+	var ids []string
+	for _, window := range windows {
+		ids = append(ids, strconv.Itoa(window.ID))
+	}
+
+	if len(args) > 0 {
+		windowID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return Window{}, err
+		}
+
+		for _, window := range windows {
+			if window.ID == windowID {
+				return window, nil
+			}
+		}
+
+		return Window{}, notFoundf("no window found with id %d, available windows: %s", windowID, strings.Join(ids, ", "))
+	}
+
+	for _, window := range windows {
+		if strings.Contains(strings.ToLower(window.Title), strings.ToLower(title)) {
+			return window, nil
+		}
+	}
+
+	return Window{}, notFoundf("no window found with title containing %q, available windows: %s", title, strings.Join(ids, ", "))
+}
+
 func NewCmdWindowClose() *cobra.Command {
+	var flags struct {
+		DryRun      bool
+		Confirm     bool
+		Interactive bool
+	}
+
 	cmd := &cobra.Command{
-		Use:     "close",
-		Aliases: []string{"remove", "rm"},
-		Short:   "Close a window",
+		Use:               "close [id...]",
+		Aliases:           []string{"remove", "rm"},
+		Short:             "Close a window",
+		ValidArgsFunction: completeWindowIDs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				if _, err := runApplescript(`tell application "Arc" to tell front window to close`); err != nil {
-					return err
-				}
-				return nil
+			windows, err := listWindows()
+			if err != nil {
+				return err
 			}
 
-			for _, id := range args {
-				windowID, err := strconv.Atoi(id)
+			if flags.Interactive && canPrompt() {
+				var options []string
+				for _, window := range windows {
+					options = append(options, fmt.Sprintf("%d %s (%d tabs)", window.ID, window.Title, window.TabCount))
+				}
+
+				indexes, err := pickItems("Windows to close", options)
 				if err != nil {
 					return err
 				}
 
+				if len(indexes) == 0 {
+					return nil
+				}
+
+				args = nil
+				for _, index := range indexes {
+					args = append(args, strconv.Itoa(windows[index].ID))
+				}
+			}
+
+			windowIDs := []int{1}
+			if len(args) > 0 {
+				windowIDs = nil
+				for _, id := range args {
+					windowID, err := strconv.Atoi(id)
+					if err != nil {
+						return err
+					}
+
+					windowIDs = append(windowIDs, windowID)
+				}
+			}
+
+			titles := make(map[int]string)
+			for _, window := range windows {
+				titles[window.ID] = window.Title
+			}
+
+			for _, windowID := range windowIDs {
+				if _, ok := titles[windowID]; !ok {
+					return notFoundf("no window found with id %d", windowID)
+				}
+			}
+
+			interactive := flags.Confirm && canPrompt()
+
+			// Close the highest ids first, so that closing a window does not shift the ids of the remaining ones.
+			sort.Sort(sort.Reverse(sort.IntSlice(windowIDs)))
+			for _, windowID := range windowIDs {
+				if flags.DryRun {
+					fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\n", windowID, titles[windowID])
+					continue
+				}
+
+				if interactive {
+					ok, err := confirm(cmd, fmt.Sprintf("Close window %d %q?", windowID, titles[windowID]))
+					if err != nil {
+						return err
+					}
+
+					if !ok {
+						continue
+					}
+				} else {
+					infof(cmd, "Closing window %d %q\n", windowID, titles[windowID])
+				}
+
 				if _, err := runApplescript(fmt.Sprintf(`tell application "Arc" to tell window %d to close`, windowID)); err != nil {
 					return err
 				}
-
 			}
+
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "only print the windows that would be closed")
+	cmd.Flags().BoolVar(&flags.Confirm, "confirm", false, "ask for confirmation before closing each window")
+	cmd.Flags().BoolVarP(&flags.Interactive, "interactive", "i", false, "pick the windows to close from a list, when run in a terminal")
 	return cmd
 }